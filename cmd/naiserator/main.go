@@ -1,27 +1,33 @@
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"fmt"
+	"net/http"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/nais/liberator/pkg/tlsutil"
 	"github.com/nais/naiserator/pkg/controllers"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	kubemetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 
-	liberator_scheme "github.com/nais/liberator/pkg/scheme"
 	"github.com/nais/naiserator/pkg/kafka"
 	"github.com/nais/naiserator/pkg/metrics"
 	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/policygraph"
 	"github.com/nais/naiserator/pkg/readonly"
+	naiserator_scheme "github.com/nais/naiserator/pkg/scheme"
 	"github.com/nais/naiserator/pkg/synchronizer"
 )
 
@@ -64,6 +70,39 @@ func run() error {
 		}
 	}
 
+	if cfg.Features.CertManager {
+		err = cfg.CertManager.Validate(cfg.Features)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = cfg.Ingress.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.StrictEgress.Validate()
+	if err != nil {
+		return err
+	}
+
+	err = cfg.Topology.Validate()
+	if err != nil {
+		return err
+	}
+
+	if cfg.Features.AccessPolicyReport && cfg.AccessPolicyReportBind == cfg.Bind {
+		return fmt.Errorf("%s must differ from %s, so that the access policy report is not exposed with the metrics", config.AccessPolicyReportBind, config.Bind)
+	}
+
+	if cfg.Features.SecurityBaseline {
+		err = cfg.SecurityBaseline.Validate()
+		if err != nil {
+			return err
+		}
+	}
+
 	var kafkaClient kafka.Interface
 
 	if cfg.Kafka.Enabled {
@@ -90,7 +129,7 @@ func run() error {
 	}
 
 	// Register CRDs with controller-tools
-	kscheme, err := liberator_scheme.All()
+	kscheme, err := naiserator_scheme.All()
 	if err != nil {
 		return err
 	}
@@ -106,33 +145,58 @@ func run() error {
 	mgr, err := ctrl.NewManager(kconfig, ctrl.Options{
 		SyncPeriod:         &cfg.Informer.FullSyncInterval,
 		Scheme:             kscheme,
-		MetricsBindAddress: cfg.Bind,
+		MetricsBindAddress: "0", // served by naiserator itself, see serveHTTP
 	})
 	if err != nil {
 		return err
 	}
 
-	stopCh := StopCh()
+	ingressAPIVersion, err := detectIngressAPIVersion(cfg.Ingress, mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	log.Infof("Creating Ingress objects with API version %s", ingressAPIVersion)
 
 	resourceOptions := resource.NewOptions()
 	resourceOptions.AccessPolicyNotAllowedCIDRs = cfg.Features.AccessPolicyNotAllowedCIDRs
+	resourceOptions.AllowedNginxAnnotations = cfg.Ingress.AllowedNginxAnnotations
 	resourceOptions.ApiServerIp = cfg.ApiServerIp
 	resourceOptions.AzureratorEnabled = cfg.Features.Azurerator
+	resourceOptions.CertManagerEnabled = cfg.Features.CertManager
+	resourceOptions.CertManager = cfg.CertManager
 	resourceOptions.ClusterName = cfg.ClusterName
 	resourceOptions.DigdiratorEnabled = cfg.Features.Digdirator
 	resourceOptions.DigdiratorHosts = cfg.ServiceHosts.Digdirator
+	resourceOptions.GatewayAPI = cfg.Features.GatewayAPI
 	resourceOptions.GatewayMappings = cfg.GatewayMappings
 	resourceOptions.GoogleCloudSQLProxyContainerImage = cfg.GoogleCloudSQLProxyContainerImage
 	resourceOptions.GoogleProjectId = cfg.GoogleProjectId
 	resourceOptions.HostAliases = cfg.HostAliases
+	resourceOptions.IngressV1beta1 = ingressAPIVersion == config.IngressAPIVersionV1beta1
 	resourceOptions.JwkerEnabled = cfg.Features.Jwker
 	resourceOptions.KafkaratorEnabled = cfg.Features.Kafkarator
+	resourceOptions.KedaEnabled = cfg.Features.Keda
+	resourceOptions.Keda = cfg.Keda
+	resourceOptions.LinkerdPolicyEnabled = cfg.Features.LinkerdPolicy
+	resourceOptions.LinkerdPolicy = cfg.LinkerdPolicy
 	resourceOptions.NativeSecrets = cfg.Features.NativeSecrets
 	resourceOptions.NetworkPolicy = cfg.Features.NetworkPolicy
+	resourceOptions.NetworkPolicyPlatform = cfg.NetworkPolicy
 	resourceOptions.Proxy = cfg.Proxy
+	resourceOptions.SecretManagerEnabled = cfg.Features.SecretManager
+	resourceOptions.ScalingScheduleEnabled = cfg.Features.ScalingSchedule
+	resourceOptions.ScalingSchedule = cfg.ScalingSchedule
 	resourceOptions.Securelogs = cfg.Securelogs
+	resourceOptions.SecurityBaselineEnabled = cfg.Features.SecurityBaseline
+	resourceOptions.SecurityBaseline = cfg.SecurityBaseline
+	resourceOptions.StatefulSetEnabled = cfg.Features.StatefulSet
+	resourceOptions.StrictEgressEnabled = cfg.Features.StrictEgress
+	resourceOptions.StrictEgress = cfg.StrictEgress
+	resourceOptions.Topology = cfg.Topology
 	resourceOptions.VaultEnabled = cfg.Features.Vault
 	resourceOptions.Vault = cfg.Vault
+	resourceOptions.VerticalPodAutoscalerEnabled = cfg.Features.VerticalPodAutoscaler
+	resourceOptions.VerticalPodAutoscaler = cfg.VerticalPodAutoscaler
 
 	if cfg.Features.GCP && len(resourceOptions.GatewayMappings) == 0 {
 		return fmt.Errorf("running in GCP and no gateway mappings defined. Will not be able to set the right gateway on the ingress")
@@ -148,14 +212,38 @@ func run() error {
 		simpleClient = readonly.NewClient(simpleClient)
 	}
 
+	var accessPolicyGraph *policygraph.Graph
+	if cfg.Features.AccessPolicyReport {
+		accessPolicyGraph = policygraph.New(cfg.ClusterName)
+	}
+
+	var resourceRecommendations *synchronizer.ResourceRecommendations
+	if cfg.Features.VerticalPodAutoscaler {
+		resourceRecommendations = synchronizer.NewResourceRecommendations()
+	}
+
+	err = mgr.Add(serveHTTP(cfg.Bind, metricsHandler()))
+	if err != nil {
+		return err
+	}
+
+	if accessPolicyGraph != nil {
+		err = mgr.Add(serveHTTP(cfg.AccessPolicyReportBind, accessPolicyHandler(accessPolicyGraph, mgrClient)))
+		if err != nil {
+			return err
+		}
+	}
+
 	applicationReconciler := controllers.NewAppReconciler(synchronizer.Synchronizer{
-		Client:          mgrClient,
-		Config:          *cfg,
-		Kafka:           kafkaClient,
-		ResourceOptions: resourceOptions,
-		RolloutMonitor:  make(map[client.ObjectKey]synchronizer.RolloutMonitor),
-		Scheme:          kscheme,
-		SimpleClient:    simpleClient,
+		Client:                  mgrClient,
+		Config:                  *cfg,
+		Kafka:                   kafkaClient,
+		ResourceOptions:         resourceOptions,
+		RolloutMonitor:          make(map[client.ObjectKey]synchronizer.RolloutMonitor),
+		Scheme:                  kscheme,
+		SimpleClient:            simpleClient,
+		AccessPolicyGraph:       accessPolicyGraph,
+		ResourceRecommendations: resourceRecommendations,
 	})
 
 	if err = applicationReconciler.SetupWithManager(mgr); err != nil {
@@ -163,32 +251,76 @@ func run() error {
 	}
 
 	naisjobReconciler := controllers.NewNaisjobReconciler(synchronizer.Synchronizer{
-		Client:          mgrClient,
-		Config:          *cfg,
-		Kafka:           kafkaClient,
-		ResourceOptions: resourceOptions,
-		RolloutMonitor:  make(map[client.ObjectKey]synchronizer.RolloutMonitor),
-		Scheme:          kscheme,
-		SimpleClient:    simpleClient,
+		Client:            mgrClient,
+		Config:            *cfg,
+		Kafka:             kafkaClient,
+		ResourceOptions:   resourceOptions,
+		RolloutMonitor:    make(map[client.ObjectKey]synchronizer.RolloutMonitor),
+		Scheme:            kscheme,
+		SimpleClient:      simpleClient,
+		AccessPolicyGraph: accessPolicyGraph,
 	})
 
 	if err = naisjobReconciler.SetupWithManager(mgr); err != nil {
 		return err
 	}
 
-	return mgr.Start(stopCh)
+	return mgr.Start(ctrl.SetupSignalHandler())
+}
+
+// detectIngressAPIVersion returns the configured Ingress API version,
+// or networking.k8s.io/v1 if the API server supports it and networking.k8s.io/v1beta1 otherwise.
+func detectIngressAPIVersion(cfg config.Ingress, mapper meta.RESTMapper) (string, error) {
+	if len(cfg.APIVersion) > 0 {
+		return cfg.APIVersion, nil
+	}
+
+	_, err := mapper.RESTMapping(schema.GroupKind{Group: networkingv1.GroupName, Kind: "Ingress"}, networkingv1.SchemeGroupVersion.Version)
+	if meta.IsNoMatchError(err) {
+		return config.IngressAPIVersionV1beta1, nil
+	} else if err != nil {
+		return "", fmt.Errorf("detect ingress API version: %w", err)
+	}
+
+	return config.IngressAPIVersionV1, nil
+}
+
+// metricsHandler serves Prometheus metrics from the controller-runtime registry.
+// The manager's own metrics server cannot be given extra handlers, so naiserator runs its own.
+func metricsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(kubemetrics.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.HTTPErrorOnError,
+	}))
+	return mux
+}
+
+// accessPolicyHandler serves the access policy report and graph. They reveal the access policies of all applications,
+// so they are served apart from the metrics.
+func accessPolicyHandler(accessPolicyGraph *policygraph.Graph, reader client.Reader) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/accesspolicy", policygraph.Handler(accessPolicyGraph, reader))
+	mux.Handle("/accesspolicy/graph", policygraph.GraphHandler(accessPolicyGraph, reader))
+	return mux
 }
 
-func StopCh() (stopCh <-chan struct{}) {
-	stop := make(chan struct{})
-	c := make(chan os.Signal, 2)
-	signal.Notify(c, []os.Signal{os.Interrupt, syscall.SIGTERM, syscall.SIGINT}...)
-	go func() {
-		<-c
-		close(stop)
-		<-c
-		os.Exit(1) // second signal. Exit directly.
-	}()
-
-	return stop
+// serveHTTP serves requests with the handler until the manager stops.
+func serveHTTP(bind string, handler http.Handler) manager.RunnableFunc {
+	return func(ctx context.Context) error {
+		server := &http.Server{
+			Addr:    bind,
+			Handler: handler,
+		}
+
+		go func() {
+			<-ctx.Done()
+			server.Close()
+		}()
+
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}
 }
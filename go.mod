@@ -1,38 +1,32 @@
 module github.com/nais/naiserator
 
 require (
-	cloud.google.com/go v0.49.0 // indirect
 	github.com/Shopify/sarama v1.28.0
 	github.com/ghodss/yaml v1.0.0
-	github.com/gogo/protobuf v1.3.0 // indirect
-	github.com/golang/groupcache v0.0.0-20190702054246-869f871628b6 // indirect
 	github.com/golang/protobuf v1.5.0
 	github.com/google/uuid v1.1.1
 	github.com/hashicorp/go-multierror v1.0.0
-	github.com/hashicorp/golang-lru v0.5.3 // indirect
-	github.com/imdario/mergo v0.3.8
+	github.com/imdario/mergo v0.3.10
 	github.com/klauspost/compress v1.12.2 // indirect
 	github.com/magiconair/properties v1.8.0
 	github.com/mitchellh/hashstructure v1.1.0
 	github.com/mitchellh/mapstructure v1.1.2
 	github.com/nais/liberator v0.0.0-20210727091739-33110929ef66
 	github.com/novln/docker-parser v0.0.0-20190306203532-b3f122c6978e
-	github.com/prometheus/client_golang v1.0.0
-	github.com/sirupsen/logrus v1.4.2
+	github.com/prometheus/client_golang v1.7.1
+	github.com/sirupsen/logrus v1.6.0
 	github.com/spf13/pflag v1.0.5
-	github.com/spf13/viper v1.3.2
+	github.com/spf13/viper v1.4.0
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210421170649-83a5a9bb288b // indirect
 	golang.org/x/net v0.0.0-20210503060351-7fd8e65b6420 // indirect
-	golang.org/x/time v0.0.0-20190921001708-c4c64cad1fd0 // indirect
-	google.golang.org/appengine v1.6.5 // indirect
 	google.golang.org/protobuf v1.26.0
-	gopkg.in/yaml.v2 v2.2.8
-	k8s.io/api v0.17.16
-	k8s.io/apimachinery v0.17.16
-	k8s.io/client-go v0.17.16
-	k8s.io/utils v0.0.0-20191114184206-e782cd3c129f
-	sigs.k8s.io/controller-runtime v0.5.0
+	gopkg.in/yaml.v2 v2.3.0
+	k8s.io/api v0.19.16
+	k8s.io/apimachinery v0.19.16
+	k8s.io/client-go v0.19.16
+	k8s.io/utils v0.0.0-20200912215256-4140de9c8800
+	sigs.k8s.io/controller-runtime v0.7.2
 )
 
 go 1.15
@@ -0,0 +1,21 @@
+// Package v1 contains API Schema definitions for the autoscaling.k8s.io v1 API group
+// +kubebuilder:object:generate=true
+// +groupName=autoscaling.k8s.io
+// +versionName=v1
+package autoscaling_k8s_io_v1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "autoscaling.k8s.io", Version: "v1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
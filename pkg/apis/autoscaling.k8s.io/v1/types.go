@@ -0,0 +1,92 @@
+package autoscaling_k8s_io_v1
+
+import (
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This is a subset of the upstream VerticalPodAutoscaler types, covering only the fields Naiserator uses.
+// See https://github.com/kubernetes/autoscaler/tree/master/vertical-pod-autoscaler
+
+func init() {
+	SchemeBuilder.Register(
+		&VerticalPodAutoscaler{},
+		&VerticalPodAutoscalerList{},
+	)
+}
+
+// UpdateMode controls whether recommendations are applied to pods.
+type UpdateMode string
+
+const (
+	// UpdateModeOff only computes recommendations.
+	UpdateModeOff UpdateMode = "Off"
+	// UpdateModeAuto applies recommendations by evicting and recreating pods.
+	UpdateModeAuto UpdateMode = "Auto"
+)
+
+// ContainerScalingMode controls whether recommendations are applied to a container.
+type ContainerScalingMode string
+
+const (
+	ContainerScalingModeAuto ContainerScalingMode = "Auto"
+	ContainerScalingModeOff  ContainerScalingMode = "Off"
+)
+
+// DefaultContainerResourcePolicy is the container name matching all containers without a policy of their own.
+const DefaultContainerResourcePolicy = "*"
+
+// +kubebuilder:object:root=true
+type VerticalPodAutoscaler struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              VerticalPodAutoscalerSpec   `json:"spec"`
+	Status            VerticalPodAutoscalerStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type VerticalPodAutoscalerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []VerticalPodAutoscaler `json:"items"`
+}
+
+type VerticalPodAutoscalerSpec struct {
+	TargetRef      *autoscalingv1.CrossVersionObjectReference `json:"targetRef"`
+	UpdatePolicy   *PodUpdatePolicy                           `json:"updatePolicy,omitempty"`
+	ResourcePolicy *PodResourcePolicy                         `json:"resourcePolicy,omitempty"`
+}
+
+type PodUpdatePolicy struct {
+	UpdateMode *UpdateMode `json:"updateMode,omitempty"`
+}
+
+type PodResourcePolicy struct {
+	ContainerPolicies []ContainerResourcePolicy `json:"containerPolicies,omitempty"`
+}
+
+// ContainerResourcePolicy bounds the recommendations applied to a container.
+type ContainerResourcePolicy struct {
+	ContainerName string                `json:"containerName,omitempty"`
+	Mode          *ContainerScalingMode `json:"mode,omitempty"`
+	MinAllowed    corev1.ResourceList   `json:"minAllowed,omitempty"`
+	MaxAllowed    corev1.ResourceList   `json:"maxAllowed,omitempty"`
+}
+
+type VerticalPodAutoscalerStatus struct {
+	Recommendation *RecommendedPodResources `json:"recommendation,omitempty"`
+}
+
+type RecommendedPodResources struct {
+	ContainerRecommendations []RecommendedContainerResources `json:"containerRecommendations,omitempty"`
+}
+
+// RecommendedContainerResources is the resource recommendation for a single container.
+type RecommendedContainerResources struct {
+	ContainerName  string              `json:"containerName,omitempty"`
+	Target         corev1.ResourceList `json:"target"`
+	LowerBound     corev1.ResourceList `json:"lowerBound,omitempty"`
+	UpperBound     corev1.ResourceList `json:"upperBound,omitempty"`
+	UncappedTarget corev1.ResourceList `json:"uncappedTarget,omitempty"`
+}
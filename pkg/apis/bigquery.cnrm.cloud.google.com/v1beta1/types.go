@@ -0,0 +1,94 @@
+package bigquery_cnrm_cloud_google_com_v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// These types replace the bigquery types in liberator, which lack tables, group access entries,
+// and a dataset list with items of the right type.
+// See https://cloud.google.com/config-connector/docs/reference/resource-docs/bigquery/bigquerydataset
+// and https://cloud.google.com/config-connector/docs/reference/resource-docs/bigquery/bigquerytable
+
+func init() {
+	SchemeBuilder.Register(
+		&BigQueryDataset{},
+		&BigQueryDatasetList{},
+		&BigQueryTable{},
+		&BigQueryTableList{},
+	)
+}
+
+type BigQueryDatasetAccess struct {
+	Role string `json:"role"`
+	// Email of a service account or user given access to the dataset
+	UserByEmail string `json:"userByEmail,omitempty"`
+	// Email of a Google group given access to the dataset
+	GroupByEmail string `json:"groupByEmail,omitempty"`
+}
+
+type BigqueryDatasetSpec struct {
+	// The datasetId of the resource. Used for creation and acquisition.
+	ResourceID string `json:"resourceID"`
+	// Physical location of GCP resource
+	Location string `json:"location"`
+	// Optional - Will also be shown in google cloud console (in browser)
+	Description string `json:"description,omitempty"`
+	// Email and role of users and groups given access to the dataset
+	Access []*BigQueryDatasetAccess `json:"access"`
+}
+
+// +kubebuilder:object:root=true
+type BigQueryDataset struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BigqueryDatasetSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type BigQueryDatasetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BigQueryDataset `json:"items"`
+}
+
+type DatasetRef struct {
+	Name string `json:"name"`
+}
+
+type TimePartitioning struct {
+	Type         string `json:"type"`
+	Field        string `json:"field,omitempty"`
+	ExpirationMs int64  `json:"expirationMs,omitempty"`
+}
+
+type View struct {
+	Query        string `json:"query"`
+	UseLegacySql bool   `json:"useLegacySql"`
+}
+
+type BigQueryTableSpec struct {
+	// The tableId of the resource. Used for creation and acquisition.
+	ResourceID  string     `json:"resourceID"`
+	DatasetRef  DatasetRef `json:"datasetRef"`
+	Description string     `json:"description,omitempty"`
+	// JSON encoded list of fields
+	Schema           string            `json:"schema,omitempty"`
+	TimePartitioning *TimePartitioning `json:"timePartitioning,omitempty"`
+	// Milliseconds since the epoch
+	ExpirationTime int64 `json:"expirationTime,omitempty"`
+	View           *View `json:"view,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type BigQueryTable struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              BigQueryTableSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type BigQueryTableList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BigQueryTable `json:"items"`
+}
@@ -0,0 +1,61 @@
+package cert_manager_io_v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This is a subset of the upstream cert-manager types, covering only the fields Naiserator uses.
+// See https://cert-manager.io/docs/reference/api-docs/
+
+func init() {
+	SchemeBuilder.Register(
+		&Certificate{},
+		&CertificateList{},
+	)
+}
+
+const (
+	CertificateConditionReady = "Ready"
+	ClusterIssuerKind         = "ClusterIssuer"
+)
+
+// +kubebuilder:object:root=true
+type Certificate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              CertificateSpec   `json:"spec"`
+	Status            CertificateStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type CertificateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Certificate `json:"items"`
+}
+
+type CertificateSpec struct {
+	// Name of the secret resource that will be populated with the signed certificate.
+	SecretName string `json:"secretName"`
+	// DNS names that the certificate is valid for.
+	DNSNames []string `json:"dnsNames,omitempty"`
+	// The issuer signing this certificate.
+	IssuerRef ObjectReference `json:"issuerRef"`
+}
+
+type ObjectReference struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+type CertificateStatus struct {
+	Conditions []CertificateCondition `json:"conditions,omitempty"`
+}
+
+type CertificateCondition struct {
+	Type    string                 `json:"type"`
+	Status  metav1.ConditionStatus `json:"status"`
+	Reason  string                 `json:"reason,omitempty"`
+	Message string                 `json:"message,omitempty"`
+}
@@ -0,0 +1,60 @@
+package cilium_io_v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This is a subset of the upstream Cilium types, covering only the fields Naiserator uses.
+// See https://docs.cilium.io/en/stable/policy/language/
+
+func init() {
+	SchemeBuilder.Register(
+		&CiliumNetworkPolicy{},
+		&CiliumNetworkPolicyList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type CiliumNetworkPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              *Rule `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type CiliumNetworkPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CiliumNetworkPolicy `json:"items"`
+}
+
+type Rule struct {
+	// Selects the endpoints this rule applies to.
+	EndpointSelector metav1.LabelSelector `json:"endpointSelector"`
+	Egress           []EgressRule         `json:"egress,omitempty"`
+}
+
+type EgressRule struct {
+	ToEndpoints []metav1.LabelSelector `json:"toEndpoints,omitempty"`
+	ToFQDNs     []FQDNSelector         `json:"toFQDNs,omitempty"`
+	ToPorts     []PortRule             `json:"toPorts,omitempty"`
+}
+
+type FQDNSelector struct {
+	MatchName    string `json:"matchName,omitempty"`
+	MatchPattern string `json:"matchPattern,omitempty"`
+}
+
+type PortRule struct {
+	Ports []PortProtocol `json:"ports,omitempty"`
+	Rules *L7Rules       `json:"rules,omitempty"`
+}
+
+type PortProtocol struct {
+	Port     string `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+type L7Rules struct {
+	DNS []FQDNSelector `json:"dns,omitempty"`
+}
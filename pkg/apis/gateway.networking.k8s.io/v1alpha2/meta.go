@@ -0,0 +1,21 @@
+// Package v1alpha2 contains API Schema definitions for the gateway.networking.k8s.io v1alpha2 API group
+// +kubebuilder:object:generate=true
+// +groupName=gateway.networking.k8s.io
+// +versionName=v1alpha2
+package gateway_networking_k8s_io_v1alpha2
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "gateway.networking.k8s.io", Version: "v1alpha2"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,83 @@
+package gateway_networking_k8s_io_v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This is a subset of the upstream Gateway API types, covering only the fields Naiserator renders.
+// See https://gateway-api.sigs.k8s.io/v1alpha2/references/spec/
+
+func init() {
+	SchemeBuilder.Register(
+		&HTTPRoute{},
+		&HTTPRouteList{},
+	)
+}
+
+type PathMatchType string
+
+const (
+	PathMatchExact      PathMatchType = "Exact"
+	PathMatchPathPrefix PathMatchType = "PathPrefix"
+)
+
+type Hostname string
+
+type PortNumber int32
+
+// +kubebuilder:object:root=true
+type HTTPRoute struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              HTTPRouteSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type HTTPRouteList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HTTPRoute `json:"items"`
+}
+
+type HTTPRouteSpec struct {
+	ParentRefs []ParentReference `json:"parentRefs,omitempty"`
+	Hostnames  []Hostname        `json:"hostnames,omitempty"`
+	Rules      []HTTPRouteRule   `json:"rules,omitempty"`
+}
+
+// ParentReference identifies the Gateway a route attaches to.
+type ParentReference struct {
+	Namespace   *string `json:"namespace,omitempty"`
+	Name        string  `json:"name"`
+	SectionName *string `json:"sectionName,omitempty"`
+}
+
+type HTTPRouteRule struct {
+	Matches     []HTTPRouteMatch `json:"matches,omitempty"`
+	BackendRefs []HTTPBackendRef `json:"backendRefs,omitempty"`
+}
+
+type HTTPRouteMatch struct {
+	Path *HTTPPathMatch `json:"path,omitempty"`
+}
+
+type HTTPPathMatch struct {
+	Type  *PathMatchType `json:"type,omitempty"`
+	Value *string        `json:"value,omitempty"`
+}
+
+type HTTPBackendRef struct {
+	BackendRef `json:",inline"`
+}
+
+type BackendRef struct {
+	BackendObjectReference `json:",inline"`
+	Weight                 *int32 `json:"weight,omitempty"`
+}
+
+// BackendObjectReference points to a Service in the same namespace as the route, unless Namespace is set.
+type BackendObjectReference struct {
+	Name      string      `json:"name"`
+	Namespace *string     `json:"namespace,omitempty"`
+	Port      *PortNumber `json:"port,omitempty"`
+}
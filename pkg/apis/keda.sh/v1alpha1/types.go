@@ -0,0 +1,82 @@
+package keda_sh_v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This is a subset of the upstream KEDA types, covering only the fields Naiserator uses.
+// See https://keda.sh/docs/concepts/scaling-deployments/
+
+func init() {
+	SchemeBuilder.Register(
+		&ScaledObject{},
+		&ScaledObjectList{},
+		&TriggerAuthentication{},
+		&TriggerAuthenticationList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type ScaledObject struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ScaledObjectSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type ScaledObjectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ScaledObject `json:"items"`
+}
+
+type ScaledObjectSpec struct {
+	ScaleTargetRef  *ScaleTarget    `json:"scaleTargetRef"`
+	PollingInterval *int32          `json:"pollingInterval,omitempty"`
+	CooldownPeriod  *int32          `json:"cooldownPeriod,omitempty"`
+	MinReplicaCount *int32          `json:"minReplicaCount,omitempty"`
+	MaxReplicaCount *int32          `json:"maxReplicaCount,omitempty"`
+	Triggers        []ScaleTriggers `json:"triggers"`
+}
+
+type ScaleTarget struct {
+	Name       string `json:"name"`
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+}
+
+type ScaleTriggers struct {
+	Type              string               `json:"type"`
+	Metadata          map[string]string    `json:"metadata"`
+	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
+}
+
+// ScaledObjectAuthRef points to the TriggerAuthentication that holds credentials for a trigger.
+type ScaledObjectAuthRef struct {
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+type TriggerAuthentication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              TriggerAuthenticationSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type TriggerAuthenticationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []TriggerAuthentication `json:"items"`
+}
+
+type TriggerAuthenticationSpec struct {
+	Env []AuthEnvironment `json:"env,omitempty"`
+}
+
+// AuthEnvironment resolves a trigger parameter from an environment variable of the scale target's container.
+type AuthEnvironment struct {
+	Parameter     string `json:"parameter"`
+	Name          string `json:"name"`
+	ContainerName string `json:"containerName,omitempty"`
+}
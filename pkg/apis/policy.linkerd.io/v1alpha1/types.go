@@ -0,0 +1,66 @@
+package policy_linkerd_io_v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This is a subset of the upstream Linkerd types, covering only the fields Naiserator uses.
+// See https://linkerd.io/2/reference/authorization-policy/
+
+func init() {
+	SchemeBuilder.Register(
+		&AuthorizationPolicy{},
+		&AuthorizationPolicyList{},
+		&MeshTLSAuthentication{},
+		&MeshTLSAuthenticationList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type AuthorizationPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              AuthorizationPolicySpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type AuthorizationPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AuthorizationPolicy `json:"items"`
+}
+
+type AuthorizationPolicySpec struct {
+	// The resource this policy applies to, typically a Server.
+	TargetRef TargetReference `json:"targetRef"`
+	// Authentications that clients must satisfy to be authorized.
+	RequiredAuthenticationRefs []TargetReference `json:"requiredAuthenticationRefs"`
+}
+
+// +kubebuilder:object:root=true
+type MeshTLSAuthentication struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              MeshTLSAuthenticationSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type MeshTLSAuthenticationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MeshTLSAuthentication `json:"items"`
+}
+
+type MeshTLSAuthenticationSpec struct {
+	// Proxy identity strings, possibly with wildcards.
+	Identities []string `json:"identities,omitempty"`
+	// References to ServiceAccounts or Namespaces whose workloads are authenticated.
+	IdentityRefs []TargetReference `json:"identityRefs,omitempty"`
+}
+
+type TargetReference struct {
+	Group     string `json:"group,omitempty"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
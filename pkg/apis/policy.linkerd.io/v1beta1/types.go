@@ -0,0 +1,43 @@
+package policy_linkerd_io_v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// This is a subset of the upstream Linkerd types, covering only the fields Naiserator uses.
+// See https://linkerd.io/2/reference/authorization-policy/
+
+func init() {
+	SchemeBuilder.Register(
+		&Server{},
+		&ServerList{},
+	)
+}
+
+const (
+	ProxyProtocolUnknown = "unknown"
+	ProxyProtocolGRPC    = "gRPC"
+)
+
+// +kubebuilder:object:root=true
+type Server struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              ServerSpec `json:"spec"`
+}
+
+// +kubebuilder:object:root=true
+type ServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Server `json:"items"`
+}
+
+type ServerSpec struct {
+	// Selects the pods exposing this server.
+	PodSelector *metav1.LabelSelector `json:"podSelector"`
+	// Name or number of the container port.
+	Port          intstr.IntOrString `json:"port"`
+	ProxyProtocol string             `json:"proxyProtocol,omitempty"`
+}
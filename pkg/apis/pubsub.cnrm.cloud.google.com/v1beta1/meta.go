@@ -0,0 +1,21 @@
+// Package v1beta1 contains API Schema definitions for the pubsub.cnrm.cloud.google.com v1beta1 API group
+// +kubebuilder:object:generate=true
+// +groupName=pubsub.cnrm.cloud.google.com
+// +versionName=v1beta1
+package pubsub_cnrm_cloud_google_com_v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is group version used to register these objects
+	GroupVersion = schema.GroupVersion{Group: "pubsub.cnrm.cloud.google.com", Version: "v1beta1"}
+
+	// SchemeBuilder is used to add go types to the GroupVersionKind scheme
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types in this group-version to the given scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
@@ -0,0 +1,72 @@
+package pubsub_cnrm_cloud_google_com_v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// These types are not available in liberator.
+// See https://cloud.google.com/config-connector/docs/reference/resource-docs/pubsub/pubsubtopic
+// and https://cloud.google.com/config-connector/docs/reference/resource-docs/pubsub/pubsubsubscription
+
+func init() {
+	SchemeBuilder.Register(
+		&PubSubTopic{},
+		&PubSubTopicList{},
+		&PubSubSubscription{},
+		&PubSubSubscriptionList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type PubSubTopic struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              PubSubTopicSpec `json:"spec"`
+}
+
+type PubSubTopicSpec struct {
+	ResourceID               string `json:"resourceID,omitempty"`
+	MessageRetentionDuration string `json:"messageRetentionDuration,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type PubSubTopicList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PubSubTopic `json:"items"`
+}
+
+// +kubebuilder:object:root=true
+type PubSubSubscription struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              PubSubSubscriptionSpec `json:"spec"`
+}
+
+type PubSubSubscriptionSpec struct {
+	ResourceID               string            `json:"resourceID,omitempty"`
+	TopicRef                 TopicRef          `json:"topicRef"`
+	AckDeadlineSeconds       int               `json:"ackDeadlineSeconds,omitempty"`
+	MessageRetentionDuration string            `json:"messageRetentionDuration,omitempty"`
+	RetainAckedMessages      bool              `json:"retainAckedMessages,omitempty"`
+	DeadLetterPolicy         *DeadLetterPolicy `json:"deadLetterPolicy,omitempty"`
+}
+
+// TopicRef refers to a topic either by the name of a PubSubTopic resource in the same namespace,
+// or by its external name on the form projects/<project>/topics/<topic>.
+type TopicRef struct {
+	Name     string `json:"name,omitempty"`
+	External string `json:"external,omitempty"`
+}
+
+type DeadLetterPolicy struct {
+	DeadLetterTopicRef  TopicRef `json:"deadLetterTopicRef"`
+	MaxDeliveryAttempts int      `json:"maxDeliveryAttempts,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type PubSubSubscriptionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PubSubSubscription `json:"items"`
+}
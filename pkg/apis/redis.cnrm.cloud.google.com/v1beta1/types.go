@@ -0,0 +1,44 @@
+package redis_cnrm_cloud_google_com_v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// These types are not available in liberator.
+// See https://cloud.google.com/config-connector/docs/reference/resource-docs/redis/redisinstance
+
+func init() {
+	SchemeBuilder.Register(
+		&RedisInstance{},
+		&RedisInstanceList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type RedisInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              RedisInstanceSpec   `json:"spec"`
+	Status            RedisInstanceStatus `json:"status,omitempty"`
+}
+
+type RedisInstanceSpec struct {
+	ResourceID      string `json:"resourceID,omitempty"`
+	Region          string `json:"region"`
+	Tier            string `json:"tier,omitempty"`
+	MemorySizeGb    int    `json:"memorySizeGb"`
+	RedisVersion    string `json:"redisVersion,omitempty"`
+	ReservedIpRange string `json:"reservedIpRange,omitempty"`
+}
+
+type RedisInstanceStatus struct {
+	Host string `json:"host,omitempty"`
+	Port int    `json:"port,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type RedisInstanceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RedisInstance `json:"items"`
+}
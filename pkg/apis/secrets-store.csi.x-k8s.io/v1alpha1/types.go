@@ -0,0 +1,47 @@
+package secrets_store_csi_x_k8s_io_v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// These types are not available in liberator.
+// See https://secrets-store-csi-driver.sigs.k8s.io/concepts.html#secretproviderclass
+
+func init() {
+	SchemeBuilder.Register(
+		&SecretProviderClass{},
+		&SecretProviderClassList{},
+	)
+}
+
+// +kubebuilder:object:root=true
+type SecretProviderClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              SecretProviderClassSpec `json:"spec"`
+}
+
+type SecretProviderClassSpec struct {
+	Provider      string            `json:"provider"`
+	Parameters    map[string]string `json:"parameters,omitempty"`
+	SecretObjects []SecretObject    `json:"secretObjects,omitempty"`
+}
+
+// SecretObject defines a Kubernetes secret that is kept in sync with the mounted contents.
+type SecretObject struct {
+	SecretName string             `json:"secretName"`
+	Type       string             `json:"type"`
+	Data       []SecretObjectData `json:"data,omitempty"`
+}
+
+type SecretObjectData struct {
+	ObjectName string `json:"objectName"`
+	Key        string `json:"key"`
+}
+
+// +kubebuilder:object:root=true
+type SecretProviderClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SecretProviderClass `json:"items"`
+}
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+
 	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
 	"github.com/nais/naiserator/pkg/synchronizer"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -18,7 +20,7 @@ func NewAppReconciler(synchronizer synchronizer.Synchronizer) *ApplicationReconc
 // +kubebuilder:rbac:groups=nais.io,resources=Applications/status,verbs=get;update;patch;create
 // +kubebuilder:rbac:groups=*,resources=events,verbs=get;list;watch;create;update
 
-func (r *ApplicationReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+func (r *ApplicationReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
 	return r.Synchronizer.ReconcileApplication(req)
 }
 
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	"github.com/nais/naiserator/pkg/synchronizer"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -19,7 +21,7 @@ func NewNaisjobReconciler(synchronizer synchronizer.Synchronizer) *NaisjobReconc
 // +kubebuilder:rbac:groups=nais.io,resources=Naisjobs/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=*,resources=events,verbs=get;list;watch;create;update
 
-func (r *NaisjobReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+func (r *NaisjobReconciler) Reconcile(_ context.Context, req ctrl.Request) (ctrl.Result, error) {
 	return r.Synchronizer.ReconcileNaisjob(req)
 }
 
@@ -54,6 +54,17 @@ type Features struct {
 	Kafkarator                  bool     `json:"kafkarator"`
 	Digdirator                  bool     `json:"digdirator"`
 	GCP                         bool     `json:"gcp"`
+	SecretManager               bool     `json:"secret-manager"`
+	GatewayAPI                  bool     `json:"gateway-api"`
+	CertManager                 bool     `json:"cert-manager"`
+	StrictEgress                bool     `json:"strict-egress"`
+	LinkerdPolicy               bool     `json:"linkerd-policy"`
+	AccessPolicyReport          bool     `json:"access-policy-report"`
+	Keda                        bool     `json:"keda"`
+	ScalingSchedule             bool     `json:"scaling-schedule"`
+	SecurityBaseline            bool     `json:"security-baseline"`
+	StatefulSet                 bool     `json:"statefulset"`
+	VerticalPodAutoscaler       bool     `json:"vertical-pod-autoscaler"`
 }
 
 type Securelogs struct {
@@ -73,9 +84,165 @@ type Vault struct {
 	KeyValuePath       string `json:"kv-path"`
 }
 
+// CertManager configures certificates for ingress hosts that are not covered by any gateway mapping.
+type CertManager struct {
+	ClusterIssuer string `json:"cluster-issuer"`
+	IngressClass  string `json:"ingress-class"`
+	// Gateway serving custom domains when routing with the Gateway API.
+	// Its HTTPS listeners must refer to the certificate secrets in application namespaces.
+	Gateway Gateway `json:"gateway"`
+}
+
+// Label is a single Kubernetes label.
+type Label struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Labels are configured as a list instead of a map, because the configuration loader splits map keys on dots,
+// which are common in label keys such as "kubernetes.io/metadata.name".
+type Labels []Label
+
+// Map returns the labels as a map. Nil labels yield a nil map.
+func (l Labels) Map() map[string]string {
+	if l == nil {
+		return nil
+	}
+	labels := make(map[string]string, len(l))
+	for _, label := range l {
+		labels[label.Key] = label.Value
+	}
+	return labels
+}
+
+// NetworkPolicyPeer selects platform pods that all applications communicate with.
+// A nil namespace selector matches pods in the application namespace, and an empty one matches all namespaces.
+type NetworkPolicyPeer struct {
+	Name              string `json:"name"`
+	NamespaceSelector Labels `json:"namespaceSelector"`
+	PodSelector       Labels `json:"podSelector"`
+}
+
+// IngressController selects the nginx ingress controller pods serving an ingress class.
+type IngressController struct {
+	NamespaceSelector Labels `json:"namespaceSelector"`
+	// Pod label whose value is the ingress class.
+	ClassLabel string `json:"classLabel"`
+}
+
+// NetworkPolicy describes the platform layout that generated network policies must allow.
+// Unset fields fall back to the standard NAIS layout.
+type NetworkPolicy struct {
+	IngressPeers      []NetworkPolicyPeer `json:"ingress-peers"`
+	EgressPeers       []NetworkPolicyPeer `json:"egress-peers"`
+	IngressController *IngressController  `json:"ingress-controller"`
+}
+
+// LinkerdPolicy configures Linkerd authorization policies generated from access policies.
+type LinkerdPolicy struct {
+	// Namespaces running platform components, such as ingress controllers, that may reach any application.
+	// Defaults to the namespaces of the platform components allowed by network policies in the standard NAIS layout:
+	// Prometheus, the nginx ingress controllers, and the Linkerd viz extension.
+	PlatformNamespaces []string `json:"platform-namespaces"`
+}
+
+// Keda configures event-driven autoscaling with KEDA ScaledObjects.
+type Keda struct {
+	// Address of the Prometheus server queried by prometheus triggers.
+	PrometheusAddress string `json:"prometheus-address"`
+}
+
+// ScalingSchedule configures how far applications are scaled down outside of their scheduled hours.
+type ScalingSchedule struct {
+	// Allow applications to be scaled down to zero replicas.
+	ScaleToZero bool `json:"scale-to-zero"`
+}
+
+// VerticalPodAutoscaler configures resource recommendations from VerticalPodAutoscalers.
+type VerticalPodAutoscaler struct {
+	// Allow applications to have recommendations applied automatically, bounded by the minimum and maximum resources.
+	AllowAuto bool   `json:"allow-auto"`
+	MinCPU    string `json:"min-cpu"`
+	MinMemory string `json:"min-memory"`
+	MaxCPU    string `json:"max-cpu"`
+	MaxMemory string `json:"max-memory"`
+	// Report resource requests that differ from the recommendation by more than this factor.
+	ReportThreshold float64 `json:"report-threshold"`
+}
+
+// Settings of the security baseline that applications may be allowed to relax.
+const (
+	RelaxRunAsNonRoot           = "run-as-non-root"
+	RelaxCapabilities           = "capabilities"
+	RelaxPrivilegeEscalation    = "privilege-escalation"
+	RelaxSeccompProfile         = "seccomp-profile"
+	RelaxReadOnlyRootFilesystem = "read-only-root-filesystem"
+)
+
+// SecurityBaseline is the security context applied to every container.
+type SecurityBaseline struct {
+	RunAsNonRoot          bool     `json:"run-as-non-root"`
+	DropCapabilities      []string `json:"drop-capabilities"`
+	NoPrivilegeEscalation bool     `json:"no-privilege-escalation"`
+	// Seccomp profile of the pod, e.g. runtime/default; empty to leave it unset.
+	SeccompProfile         string `json:"seccomp-profile"`
+	ReadOnlyRootFilesystem bool   `json:"read-only-root-filesystem"`
+	// User ID of platform sidecars and init containers that do not set their own, when containers must run as non-root.
+	PlatformRunAsUser int64 `json:"platform-run-as-user"`
+	// Settings that applications may relax for their own container.
+	Relaxable []string `json:"relaxable"`
+}
+
+// Topology configures how the replicas of applications are spread across zones and nodes.
+type Topology struct {
+	ZoneKey string `json:"zone-key"`
+	// Maximum difference in the number of replicas between two zones or nodes; zero disables the constraint.
+	ZoneMaxSkew int `json:"zone-max-skew"`
+	NodeMaxSkew int `json:"node-max-skew"`
+	// Either DoNotSchedule or ScheduleAnyway.
+	ZoneWhenUnsatisfiable string `json:"zone-when-unsatisfiable"`
+	NodeWhenUnsatisfiable string `json:"node-when-unsatisfiable"`
+	// Either preferred, required or disabled.
+	AntiAffinity string `json:"anti-affinity"`
+}
+
+// Egress providers supported in strict egress mode.
+const (
+	StrictEgressProviderIPBlock = "ip-block"
+	StrictEgressProviderCilium  = "cilium"
+)
+
+// StrictEgress configures how egress is limited to declared external hosts.
+type StrictEgress struct {
+	Provider     string   `json:"provider"`
+	AllowedHosts []string `json:"allowed-hosts"`
+	// Default enables strict egress in namespaces that do not opt in or out with an annotation.
+	Default bool `json:"default"`
+}
+
+// Ingress API versions that naiserator can create Ingress objects with.
+const (
+	IngressAPIVersionV1      = "networking.k8s.io/v1"
+	IngressAPIVersionV1beta1 = "networking.k8s.io/v1beta1"
+)
+
+type Ingress struct {
+	AllowedNginxAnnotations []string `json:"allowed-nginx-annotations"`
+	// APIVersion of created Ingress objects. Detected from the API server if empty.
+	APIVersion string `json:"api-version"`
+}
+
 type GatewayMapping struct {
-	DomainSuffix string `json:"domainSuffix"`
-	IngressClass string `json:"ingressClass"` // Nginx
+	DomainSuffix string  `json:"domainSuffix"`
+	IngressClass string  `json:"ingressClass"` // Nginx
+	Gateway      Gateway `json:"gateway"`      // Gateway API
+}
+
+// Gateway references a Gateway API Gateway object, and the pods that implement it.
+type Gateway struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	PodLabels Labels `json:"podLabels"`
 }
 
 type HostAlias struct {
@@ -95,71 +262,125 @@ type ServiceHosts struct {
 }
 
 type Config struct {
-	DryRun                            bool             `json:"dry-run"`
-	Bind                              string           `json:"bind"`
-	Informer                          Informer         `json:"informer"`
-	Synchronizer                      Synchronizer     `json:"synchronizer"`
-	Kubeconfig                        string           `json:"kubeconfig"`
-	ClusterName                       string           `json:"cluster-name"`
-	GoogleProjectId                   string           `json:"google-project-id"`
-	GoogleCloudSQLProxyContainerImage string           `json:"google-cloud-sql-proxy-container-image"`
-	ApiServerIp                       string           `json:"api-server-ip"`
-	Ratelimit                         Ratelimit        `json:"ratelimit"`
-	Log                               Log              `json:"log"`
-	Features                          Features         `json:"features"`
-	Securelogs                        Securelogs       `json:"securelogs"`
-	Proxy                             Proxy            `json:"proxy"`
-	Vault                             Vault            `json:"vault"`
-	Kafka                             Kafka            `json:"kafka"`
-	HostAliases                       []HostAlias      `json:"host-aliases"`
-	GatewayMappings                   []GatewayMapping `json:"gateway-mappings"`
-	ServiceHosts                      ServiceHosts     `json:"service-hosts"`
+	DryRun                            bool                  `json:"dry-run"`
+	Bind                              string                `json:"bind"`
+	AccessPolicyReportBind            string                `json:"access-policy-report-bind"`
+	Informer                          Informer              `json:"informer"`
+	Synchronizer                      Synchronizer          `json:"synchronizer"`
+	Kubeconfig                        string                `json:"kubeconfig"`
+	ClusterName                       string                `json:"cluster-name"`
+	GoogleProjectId                   string                `json:"google-project-id"`
+	GoogleCloudSQLProxyContainerImage string                `json:"google-cloud-sql-proxy-container-image"`
+	ApiServerIp                       string                `json:"api-server-ip"`
+	Ratelimit                         Ratelimit             `json:"ratelimit"`
+	Log                               Log                   `json:"log"`
+	LinkerdPolicy                     LinkerdPolicy         `json:"linkerd-policy"`
+	NetworkPolicy                     NetworkPolicy         `json:"network-policy"`
+	Features                          Features              `json:"features"`
+	Securelogs                        Securelogs            `json:"securelogs"`
+	Proxy                             Proxy                 `json:"proxy"`
+	Vault                             Vault                 `json:"vault"`
+	CertManager                       CertManager           `json:"cert-manager"`
+	Keda                              Keda                  `json:"keda"`
+	ScalingSchedule                   ScalingSchedule       `json:"scaling-schedule"`
+	SecurityBaseline                  SecurityBaseline      `json:"security-baseline"`
+	Kafka                             Kafka                 `json:"kafka"`
+	HostAliases                       []HostAlias           `json:"host-aliases"`
+	GatewayMappings                   []GatewayMapping      `json:"gateway-mappings"`
+	Ingress                           Ingress               `json:"ingress"`
+	StrictEgress                      StrictEgress          `json:"strict-egress"`
+	ServiceHosts                      ServiceHosts          `json:"service-hosts"`
+	Topology                          Topology              `json:"topology"`
+	VerticalPodAutoscaler             VerticalPodAutoscaler `json:"vertical-pod-autoscaler"`
 }
 
 const (
-	ApiServerIp                         = "api-server-ip"
-	Bind                                = "bind"
-	ClusterName                         = "cluster-name"
-	DryRun                              = "dry-run"
-	FeaturesAccessPolicyNotAllowedCIDRs = "features.access-policy-not-allowed-cidrs"
-	FeaturesAzurerator                  = "features.azurerator"
-	FeaturesDigdirator                  = "features.digdirator"
-	FeaturesGCP                         = "features.gcp"
-	FeaturesJwker                       = "features.jwker"
-	FeaturesKafkarator                  = "features.kafkarator"
-	FeaturesLinkerd                     = "features.linkerd"
-	FeaturesNativeSecrets               = "features.native-secrets"
-	FeaturesNetworkPolicy               = "features.network-policy"
-	FeaturesVault                       = "features.vault"
-	GoogleCloudSQLProxyContainerImage   = "google-cloud-sql-proxy-container-image"
-	GoogleProjectId                     = "google-project-id"
-	InformerFullSynchronizationInterval = "informer.full-sync-interval"
-	KafkaBrokers                        = "kafka.brokers"
-	KafkaEnabled                        = "kafka.enabled"
-	KafkaLogVerbosity                   = "kafka.log-verbosity"
-	KafkaTLSCAPath                      = "kafka.tls.ca-path"
-	KafkaTLSCertificatePath             = "kafka.tls.certificate-path"
-	KafkaTLSEnabled                     = "kafka.tls.enabled"
-	KafkaTLSInsecure                    = "kafka.tls.insecure"
-	KafkaTLSPrivateKeyPath              = "kafka.tls.private-key-path"
-	KafkaTopic                          = "kafka.topic"
-	KubeConfig                          = "kubeconfig"
-	ProxyAddress                        = "proxy.address"
-	ProxyExclude                        = "proxy.exclude"
-	RateLimitBurst                      = "ratelimit.burst"
-	RateLimitQPS                        = "ratelimit.qps"
-	SecurelogsConfigMapReloadImage      = "securelogs.configmap-reload-image"
-	SecurelogsFluentdImage              = "securelogs.fluentd-image"
-	ServiceHostsAzurerator              = "service-hosts.azurerator"
-	ServiceHostsDigdirator              = "service-hosts.digdirator"
-	ServiceHostsJwker                   = "service-hosts.jwker"
-	SynchronizerRolloutCheckInterval    = "synchronizer.rollout-check-interval"
-	SynchronizerRolloutTimeout          = "synchronizer.rollout-timeout"
-	SynchronizerSynchronizationTimeout  = "synchronizer.synchronization-timeout"
-	VaultAddress                        = "vault.address"
-	VaultAuthPath                       = "vault.auth-path"
-	VaultInitContainerImage             = "vault.init-container-image"
-	VaultKvPath                         = "vault.kv-path"
+	AccessPolicyReportBind               = "access-policy-report-bind"
+	ApiServerIp                          = "api-server-ip"
+	Bind                                 = "bind"
+	CertManagerClusterIssuer             = "cert-manager.cluster-issuer"
+	CertManagerGatewayName               = "cert-manager.gateway.name"
+	CertManagerGatewayNamespace          = "cert-manager.gateway.namespace"
+	CertManagerIngressClass              = "cert-manager.ingress-class"
+	ClusterName                          = "cluster-name"
+	DryRun                               = "dry-run"
+	FeaturesAccessPolicyNotAllowedCIDRs  = "features.access-policy-not-allowed-cidrs"
+	FeaturesAccessPolicyReport           = "features.access-policy-report"
+	FeaturesAzurerator                   = "features.azurerator"
+	FeaturesCertManager                  = "features.cert-manager"
+	FeaturesDigdirator                   = "features.digdirator"
+	FeaturesGCP                          = "features.gcp"
+	FeaturesGatewayAPI                   = "features.gateway-api"
+	FeaturesJwker                        = "features.jwker"
+	FeaturesKafkarator                   = "features.kafkarator"
+	FeaturesKeda                         = "features.keda"
+	FeaturesLinkerd                      = "features.linkerd"
+	FeaturesLinkerdPolicy                = "features.linkerd-policy"
+	FeaturesNativeSecrets                = "features.native-secrets"
+	FeaturesNetworkPolicy                = "features.network-policy"
+	FeaturesScalingSchedule              = "features.scaling-schedule"
+	FeaturesSecretManager                = "features.secret-manager"
+	FeaturesSecurityBaseline             = "features.security-baseline"
+	FeaturesStatefulSet                  = "features.statefulset"
+	FeaturesStrictEgress                 = "features.strict-egress"
+	FeaturesVault                        = "features.vault"
+	FeaturesVerticalPodAutoscaler        = "features.vertical-pod-autoscaler"
+	GoogleCloudSQLProxyContainerImage    = "google-cloud-sql-proxy-container-image"
+	GoogleProjectId                      = "google-project-id"
+	InformerFullSynchronizationInterval  = "informer.full-sync-interval"
+	IngressAllowedNginxAnnotations       = "ingress.allowed-nginx-annotations"
+	IngressAPIVersion                    = "ingress.api-version"
+	KafkaBrokers                         = "kafka.brokers"
+	KafkaEnabled                         = "kafka.enabled"
+	KafkaLogVerbosity                    = "kafka.log-verbosity"
+	KafkaTLSCAPath                       = "kafka.tls.ca-path"
+	KafkaTLSCertificatePath              = "kafka.tls.certificate-path"
+	KafkaTLSEnabled                      = "kafka.tls.enabled"
+	KafkaTLSInsecure                     = "kafka.tls.insecure"
+	KafkaTLSPrivateKeyPath               = "kafka.tls.private-key-path"
+	KafkaTopic                           = "kafka.topic"
+	KedaPrometheusAddress                = "keda.prometheus-address"
+	KubeConfig                           = "kubeconfig"
+	LinkerdPolicyPlatformNamespaces      = "linkerd-policy.platform-namespaces"
+	ProxyAddress                         = "proxy.address"
+	ProxyExclude                         = "proxy.exclude"
+	RateLimitBurst                       = "ratelimit.burst"
+	RateLimitQPS                         = "ratelimit.qps"
+	ScalingScheduleScaleToZero           = "scaling-schedule.scale-to-zero"
+	SecurelogsConfigMapReloadImage       = "securelogs.configmap-reload-image"
+	SecurelogsFluentdImage               = "securelogs.fluentd-image"
+	SecurityBaselineDropCapabilities     = "security-baseline.drop-capabilities"
+	SecurityBaselineNoPrivEscalation     = "security-baseline.no-privilege-escalation"
+	SecurityBaselinePlatformRunAsUser    = "security-baseline.platform-run-as-user"
+	SecurityBaselineReadOnlyRoot         = "security-baseline.read-only-root-filesystem"
+	SecurityBaselineRelaxable            = "security-baseline.relaxable"
+	SecurityBaselineRunAsNonRoot         = "security-baseline.run-as-non-root"
+	SecurityBaselineSeccompProfile       = "security-baseline.seccomp-profile"
+	ServiceHostsAzurerator               = "service-hosts.azurerator"
+	ServiceHostsDigdirator               = "service-hosts.digdirator"
+	ServiceHostsJwker                    = "service-hosts.jwker"
+	StrictEgressAllowedHosts             = "strict-egress.allowed-hosts"
+	StrictEgressDefault                  = "strict-egress.default"
+	StrictEgressProvider                 = "strict-egress.provider"
+	SynchronizerRolloutCheckInterval     = "synchronizer.rollout-check-interval"
+	SynchronizerRolloutTimeout           = "synchronizer.rollout-timeout"
+	SynchronizerSynchronizationTimeout   = "synchronizer.synchronization-timeout"
+	TopologyAntiAffinity                 = "topology.anti-affinity"
+	TopologyNodeMaxSkew                  = "topology.node-max-skew"
+	TopologyNodeWhenUnsatisfiable        = "topology.node-when-unsatisfiable"
+	TopologyZoneKey                      = "topology.zone-key"
+	TopologyZoneMaxSkew                  = "topology.zone-max-skew"
+	TopologyZoneWhenUnsatisfiable        = "topology.zone-when-unsatisfiable"
+	VaultAddress                         = "vault.address"
+	VaultAuthPath                        = "vault.auth-path"
+	VaultInitContainerImage              = "vault.init-container-image"
+	VaultKvPath                          = "vault.kv-path"
+	VerticalPodAutoscalerAllowAuto       = "vertical-pod-autoscaler.allow-auto"
+	VerticalPodAutoscalerMaxCPU          = "vertical-pod-autoscaler.max-cpu"
+	VerticalPodAutoscalerMaxMemory       = "vertical-pod-autoscaler.max-memory"
+	VerticalPodAutoscalerMinCPU          = "vertical-pod-autoscaler.min-cpu"
+	VerticalPodAutoscalerMinMemory       = "vertical-pod-autoscaler.min-memory"
+	VerticalPodAutoscalerReportThreshold = "vertical-pod-autoscaler.report-threshold"
 )
 
 func bindNAIS() {
@@ -189,19 +410,31 @@ func init() {
 	flag.Bool(DryRun, false, "set to true to run without any actual changes to the cluster")
 	flag.String(KubeConfig, "", "path to Kubernetes config file")
 	flag.String(Bind, "127.0.0.1:8080", "ip:port where http requests are served")
+	flag.String(AccessPolicyReportBind, "127.0.0.1:8081", "ip:port where the access policy report and graph are served if enabled; they are served without authentication and reveal the access policies of all applications, so only bind to an address reachable by trusted clients")
 	flag.String(ClusterName, "cluster-name-unconfigured", "cluster name as presented to deployed applications")
 	flag.String(GoogleProjectId, "", "GCP project-id to store google service accounts")
 	flag.String(GoogleCloudSQLProxyContainerImage, "", "Docker image of Cloud SQL Proxy container")
 	flag.String(ApiServerIp, "", "IP to master in GCP, e.g. 172.16.0.2/32 for GCP")
 	flag.Bool(FeaturesLinkerd, false, "enable creation of Linkerd-specific resources")
+	flag.Bool(FeaturesLinkerdPolicy, false, "enforce inbound access policies with Linkerd authorization policies")
+	flag.Bool(FeaturesAccessPolicyReport, false, "report access policy rules that are inconsistent between applications, and serve the access policy graph")
 	flag.StringSlice(FeaturesAccessPolicyNotAllowedCIDRs, []string{""}, "CIDRs that should not be included within the allowed IP Block rule for network policy")
 	flag.Bool(FeaturesNativeSecrets, false, "enable use of native secrets")
 	flag.Bool(FeaturesNetworkPolicy, false, "enable creation of network policies")
 	flag.Bool(FeaturesVault, false, "enable use of vault secret injection")
 	flag.Bool(FeaturesGCP, false, "running in gcp and enable use of CNRM resources")
+	flag.Bool(FeaturesSecretManager, false, "enable mounting of Google Secret Manager secrets through the CSI secret store driver")
+	flag.Bool(FeaturesGatewayAPI, false, "create Gateway API HTTPRoutes instead of Ingresses")
+	flag.Bool(FeaturesStrictEgress, false, "only allow egress to external hosts declared in the access policy")
+	flag.Bool(FeaturesCertManager, false, "enable creation of cert-manager Certificates for custom ingress domains")
 	flag.Bool(FeaturesJwker, false, "enable creation of Jwker resources and secret injection")
 	flag.Bool(FeaturesAzurerator, false, "enable creation of AzureAdApplication resources and secret injection")
 	flag.Bool(FeaturesKafkarator, false, "enable Kafkarator secret injection")
+	flag.Bool(FeaturesKeda, false, "allow applications to autoscale with KEDA ScaledObjects instead of HorizontalPodAutoscalers")
+	flag.Bool(FeaturesScalingSchedule, false, "allow applications to scale down outside of scheduled hours")
+	flag.Bool(FeaturesSecurityBaseline, false, "apply the security baseline to every container")
+	flag.Bool(FeaturesStatefulSet, false, "allow applications to run as StatefulSets with persistent volumes")
+	flag.Bool(FeaturesVerticalPodAutoscaler, false, "create VerticalPodAutoscalers and report their resource recommendations")
 	flag.Bool(FeaturesDigdirator, false, "enable creation of IDPorten client resources and secret injection")
 
 	flag.StringSlice(ServiceHostsAzurerator, []string{}, "list of hosts to output to ServiceEntry for Applications using Azurerator")
@@ -220,6 +453,22 @@ func init() {
 	flag.String(SecurelogsFluentdImage, "", "Docker image used for secure log fluentd sidecar")
 	flag.String(SecurelogsConfigMapReloadImage, "", "Docker image used for secure log configmap reload sidecar")
 
+	flag.StringSlice(IngressAllowedNginxAnnotations, []string{}, "restrict the nginx.ingress.kubernetes.io annotations that applications may set directly; all except the *-snippet annotations are allowed if empty")
+	flag.String(IngressAPIVersion, "", "API version of created Ingress objects; either 'networking.k8s.io/v1' or 'networking.k8s.io/v1beta1', detected from the API server if empty")
+
+	flag.StringSlice(LinkerdPolicyPlatformNamespaces, []string{"nais", "nginx", "linkerd-viz"}, "namespaces whose meshed workloads may reach all applications when Linkerd authorization policies are enabled")
+
+	flag.String(StrictEgressProvider, StrictEgressProviderIPBlock, "how external hosts are allowed in strict egress mode; either 'ip-block' or 'cilium'")
+	flag.StringSlice(StrictEgressAllowedHosts, []string{}, "external hosts that all applications may reach in strict egress mode")
+	flag.Bool(StrictEgressDefault, false, "enable strict egress in namespaces without the nais.io/strict-egress annotation")
+
+	flag.String(TopologyZoneKey, "topology.kubernetes.io/zone", "node label identifying the zone of a node")
+	flag.Int(TopologyZoneMaxSkew, 1, "maximum difference in the number of replicas of an application between two zones; 0 to disable")
+	flag.String(TopologyZoneWhenUnsatisfiable, "ScheduleAnyway", "whether to schedule replicas that cannot be spread across zones; either 'DoNotSchedule' or 'ScheduleAnyway'")
+	flag.Int(TopologyNodeMaxSkew, 1, "maximum difference in the number of replicas of an application between two nodes; 0 to disable")
+	flag.String(TopologyNodeWhenUnsatisfiable, "ScheduleAnyway", "whether to schedule replicas that cannot be spread across nodes; either 'DoNotSchedule' or 'ScheduleAnyway'")
+	flag.String(TopologyAntiAffinity, "preferred", "pod anti-affinity between replicas of an application; either 'preferred', 'required' or 'disabled'")
+
 	flag.String(ProxyAddress, "", "HTTPS?_PROXY environment variable injected into containers")
 	flag.StringSlice(ProxyExclude, []string{"localhost"}, "list of hosts or domains injected into NO_PROXY environment variable")
 
@@ -228,6 +477,30 @@ func init() {
 	flag.String(VaultAuthPath, "", "path to vault kubernetes auth backend")
 	flag.String(VaultKvPath, "", "path to Vault KV mount")
 
+	flag.String(CertManagerClusterIssuer, "", "cert-manager ClusterIssuer used to sign certificates for custom ingress domains")
+	flag.String(CertManagerIngressClass, "", "ingress class serving custom ingress domains")
+	flag.String(CertManagerGatewayName, "", "Gateway serving custom ingress domains when the Gateway API is enabled")
+	flag.String(CertManagerGatewayNamespace, "", "namespace of the Gateway serving custom ingress domains")
+
+	flag.String(KedaPrometheusAddress, "", "address of the Prometheus server queried by KEDA prometheus triggers")
+
+	flag.Bool(ScalingScheduleScaleToZero, false, "allow applications to scale down to zero replicas outside of scheduled hours")
+
+	flag.Bool(SecurityBaselineRunAsNonRoot, true, "require containers to run as a non-root user")
+	flag.Int64(SecurityBaselinePlatformRunAsUser, 65534, "non-root user ID for platform sidecars and init containers whose images do not set one, such as secure logs and Vault")
+	flag.StringSlice(SecurityBaselineDropCapabilities, []string{"ALL"}, "Linux capabilities dropped from containers")
+	flag.Bool(SecurityBaselineNoPrivEscalation, true, "prevent processes in containers from gaining more privileges than their parent")
+	flag.String(SecurityBaselineSeccompProfile, "runtime/default", "seccomp profile of pods; either 'runtime/default', 'docker/default', 'unconfined', 'localhost/<path>' or empty to leave unset")
+	flag.Bool(SecurityBaselineReadOnlyRoot, false, "mount the root filesystem of containers read-only, with a writable /tmp")
+	flag.StringSlice(SecurityBaselineRelaxable, []string{}, "settings applications may relax for their own container; any of 'run-as-non-root', 'capabilities', 'privilege-escalation', 'seccomp-profile' and 'read-only-root-filesystem'")
+
+	flag.Bool(VerticalPodAutoscalerAllowAuto, false, "allow applications to have resource recommendations applied automatically")
+	flag.String(VerticalPodAutoscalerMinCPU, "", "lowest CPU request applied automatically from recommendations")
+	flag.String(VerticalPodAutoscalerMinMemory, "", "lowest memory request applied automatically from recommendations")
+	flag.String(VerticalPodAutoscalerMaxCPU, "", "highest CPU request applied automatically from recommendations")
+	flag.String(VerticalPodAutoscalerMaxMemory, "", "highest memory request applied automatically from recommendations")
+	flag.Float64(VerticalPodAutoscalerReportThreshold, 2, "report resource requests that differ from the recommendation by more than this factor")
+
 	flag.Bool(KafkaEnabled, false, "Enable connection to kafka")
 	flag.Bool(KafkaTLSEnabled, false, "Use TLS for connecting to Kafka.")
 	flag.Bool(KafkaTLSInsecure, false, "Allow insecure Kafka TLS connections.")
@@ -1 +1,71 @@
 package config_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const configFile = `
+network-policy:
+  ingress-peers:
+    - name: vmagent
+      namespaceSelector:
+        - key: kubernetes.io/metadata.name
+          value: monitoring
+      podSelector:
+        - key: app.kubernetes.io/name
+          value: vmagent
+  ingress-controller:
+    namespaceSelector:
+      - key: kubernetes.io/metadata.name
+        value: traefik
+    classLabel: app.kubernetes.io/name
+cert-manager:
+  gateway:
+    name: custom-gateway
+    podLabels:
+      - key: gateway.networking.k8s.io/gateway-name
+        value: custom-gateway
+`
+
+// Label keys containing dots must survive the configuration loader, which splits map keys on dots.
+func TestNewWithDottedLabelKeys(t *testing.T) {
+	dir, err := ioutil.TempDir("", "naiserator-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "naiserator.yaml"), []byte(configFile), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	viper.AddConfigPath(dir)
+
+	args := os.Args
+	os.Args = []string{"naiserator"}
+	defer func() { os.Args = args }()
+
+	cfg, err := config.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Len(t, cfg.NetworkPolicy.IngressPeers, 1)
+	assert.Equal(t, map[string]string{"kubernetes.io/metadata.name": "monitoring"}, cfg.NetworkPolicy.IngressPeers[0].NamespaceSelector.Map())
+	assert.Equal(t, map[string]string{"app.kubernetes.io/name": "vmagent"}, cfg.NetworkPolicy.IngressPeers[0].PodSelector.Map())
+	assert.Nil(t, cfg.NetworkPolicy.EgressPeers)
+
+	assert.NotNil(t, cfg.NetworkPolicy.IngressController)
+	assert.Equal(t, map[string]string{"kubernetes.io/metadata.name": "traefik"}, cfg.NetworkPolicy.IngressController.NamespaceSelector.Map())
+	assert.Equal(t, "app.kubernetes.io/name", cfg.NetworkPolicy.IngressController.ClassLabel)
+
+	assert.Equal(t, map[string]string{"gateway.networking.k8s.io/gateway-name": "custom-gateway"}, cfg.CertManager.Gateway.PodLabels.Map())
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hashicorp/go-multierror"
 )
@@ -27,3 +28,96 @@ func (v Vault) Validate() error {
 
 	return result.ErrorOrNil()
 }
+
+// Validate checks the settings needed to serve custom domains in the ingress mode given by features.
+func (c CertManager) Validate(features Features) error {
+	var result = &multierror.Error{}
+
+	if len(c.ClusterIssuer) == 0 {
+		multierror.Append(result, fmt.Errorf("cert-manager cluster issuer not specified"))
+	}
+
+	if features.GatewayAPI && len(c.Gateway.Name) == 0 {
+		multierror.Append(result, fmt.Errorf("cert-manager gateway not specified"))
+	}
+
+	if !features.GatewayAPI && features.Linkerd && len(c.IngressClass) == 0 {
+		multierror.Append(result, fmt.Errorf("cert-manager ingress class not specified"))
+	}
+
+	return result.ErrorOrNil()
+}
+
+func (i Ingress) Validate() error {
+	switch i.APIVersion {
+	case "", IngressAPIVersionV1, IngressAPIVersionV1beta1:
+		return nil
+	default:
+		return fmt.Errorf("ingress API version must be either '%s' or '%s'", IngressAPIVersionV1, IngressAPIVersionV1beta1)
+	}
+}
+
+func (s StrictEgress) Validate() error {
+	switch s.Provider {
+	case StrictEgressProviderIPBlock, StrictEgressProviderCilium:
+		return nil
+	default:
+		return fmt.Errorf("strict egress provider must be either '%s' or '%s'", StrictEgressProviderIPBlock, StrictEgressProviderCilium)
+	}
+}
+
+func (t Topology) Validate() error {
+	var result = &multierror.Error{}
+
+	for _, setting := range [][2]string{
+		{TopologyZoneWhenUnsatisfiable, t.ZoneWhenUnsatisfiable},
+		{TopologyNodeWhenUnsatisfiable, t.NodeWhenUnsatisfiable},
+	} {
+		if setting[1] != "DoNotSchedule" && setting[1] != "ScheduleAnyway" {
+			multierror.Append(result, fmt.Errorf("%s must be either 'DoNotSchedule' or 'ScheduleAnyway'", setting[0]))
+		}
+	}
+
+	switch t.AntiAffinity {
+	case "preferred", "required", "disabled":
+	default:
+		multierror.Append(result, fmt.Errorf("%s must be one of 'preferred', 'required' or 'disabled'", TopologyAntiAffinity))
+	}
+
+	if t.ZoneMaxSkew > 0 && len(t.ZoneKey) == 0 {
+		multierror.Append(result, fmt.Errorf("%s must be set to spread replicas across zones", TopologyZoneKey))
+	}
+
+	return result.ErrorOrNil()
+}
+
+// ValidSeccompProfile returns true for the seccomp profiles accepted by the seccomp annotations.
+func ValidSeccompProfile(profile string) bool {
+	switch profile {
+	case "runtime/default", "docker/default", "unconfined":
+		return true
+	}
+	return strings.HasPrefix(profile, "localhost/") && len(profile) > len("localhost/")
+}
+
+func (s SecurityBaseline) Validate() error {
+	var result = &multierror.Error{}
+
+	if len(s.SeccompProfile) > 0 && !ValidSeccompProfile(s.SeccompProfile) {
+		multierror.Append(result, fmt.Errorf("%s must be one of 'runtime/default', 'docker/default', 'unconfined' or 'localhost/<path>'", SecurityBaselineSeccompProfile))
+	}
+
+	if s.RunAsNonRoot && s.PlatformRunAsUser <= 0 {
+		multierror.Append(result, fmt.Errorf("%s must be a non-root user ID", SecurityBaselinePlatformRunAsUser))
+	}
+
+	for _, setting := range s.Relaxable {
+		switch setting {
+		case RelaxRunAsNonRoot, RelaxCapabilities, RelaxPrivilegeEscalation, RelaxSeccompProfile, RelaxReadOnlyRootFilesystem:
+		default:
+			multierror.Append(result, fmt.Errorf("%s: unknown setting '%s'", SecurityBaselineRelaxable, setting))
+		}
+	}
+
+	return result.ErrorOrNil()
+}
@@ -0,0 +1,303 @@
+package policygraph
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Vertex types, in addition to TypeApplication and TypeNaisjob.
+const (
+	TypeIngress   = "ingress"
+	TypeExternal  = "external"
+	TypeNamespace = "namespace" // all applications in a namespace, as referred to by wildcard rules
+	TypeRemote    = "remote"    // application in another cluster
+	TypeMissing   = "missing"   // application referred to by a rule, but not found in this cluster
+)
+
+// Edge types.
+const (
+	EdgeAccess   = "access"
+	EdgeIngress  = "ingress"
+	EdgeExternal = "external"
+)
+
+// Vertex is an application, ingress or external host in the exported graph.
+type Vertex struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Cluster   string `json:"cluster,omitempty"`
+	Team      string `json:"team,omitempty"`
+}
+
+// Edge is a permitted traffic flow in the exported graph.
+// Access edges between applications record which side declared the rule.
+type Edge struct {
+	From         string `json:"from"`
+	To           string `json:"to"`
+	Type         string `json:"type"`
+	Outbound     bool   `json:"outbound"`
+	Inbound      bool   `json:"inbound"`
+	CrossCluster bool   `json:"crossCluster"`
+}
+
+// Export is the access policy graph of a cluster.
+type Export struct {
+	Cluster  string   `json:"cluster"`
+	Vertices []Vertex `json:"vertices"`
+	Edges    []Edge   `json:"edges"`
+}
+
+// Filter selects applications by namespace and team. Empty lists match everything.
+type Filter struct {
+	Namespaces []string
+	Teams      []string
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (f Filter) matches(node *Node) bool {
+	if len(f.Namespaces) > 0 && !contains(f.Namespaces, node.Namespace) {
+		return false
+	}
+	if len(f.Teams) > 0 && !contains(f.Teams, node.Team) {
+		return false
+	}
+	return true
+}
+
+func (g *Graph) vertexID(cluster, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s", cluster, namespace, name)
+}
+
+// nodeVertex returns the vertex of an application or naisjob.
+// Naisjobs may have the same name as an application, so their IDs include the node type.
+func (g *Graph) nodeVertex(node *Node) Vertex {
+	id := g.vertexID(g.clusterName, node.Namespace, node.Name)
+	if node.Type == TypeNaisjob {
+		id = g.vertexID(g.clusterName, node.Namespace, TypeNaisjob+"/"+node.Name)
+	}
+	return Vertex{
+		ID:        id,
+		Type:      node.Type,
+		Name:      node.Name,
+		Namespace: node.Namespace,
+		Cluster:   g.clusterName,
+		Team:      node.Team,
+	}
+}
+
+// vertexNode returns the application or naisjob in this cluster a vertex represents, if any.
+func (g *Graph) vertexNode(vertex Vertex) *Node {
+	if vertex.Cluster != g.clusterName {
+		return nil
+	}
+	return g.nodes[Key{Type: vertex.Type, Namespace: vertex.Namespace, Name: vertex.Name}]
+}
+
+// ruleVertices returns the vertices a rule, declared by node, refers to.
+// A rule refers to both the application and the naisjob with the given name, if both exist.
+func (g *Graph) ruleVertices(node *Node, rule nais_io_v1.AccessPolicyRule) []Vertex {
+	vertex := Vertex{
+		Name:      rule.Application,
+		Namespace: rule.Namespace,
+		Cluster:   rule.Cluster,
+	}
+	if len(vertex.Namespace) == 0 {
+		vertex.Namespace = node.Namespace
+	}
+	if len(vertex.Cluster) == 0 {
+		vertex.Cluster = g.clusterName
+	}
+	vertex.ID = g.vertexID(vertex.Cluster, vertex.Namespace, vertex.Name)
+
+	switch {
+	case !rule.MatchesCluster(g.clusterName):
+		vertex.Type = TypeRemote
+	case rule.Application == wildcard:
+		vertex.Type = TypeNamespace
+	default:
+		peers := g.named(client.ObjectKey{Namespace: vertex.Namespace, Name: vertex.Name})
+		if len(peers) == 0 {
+			vertex.Type = TypeMissing
+			break
+		}
+		vertices := make([]Vertex, 0, len(peers))
+		for _, peer := range peers {
+			vertices = append(vertices, g.nodeVertex(peer))
+		}
+		return vertices
+	}
+
+	return []Vertex{vertex}
+}
+
+// Export builds the access policy graph of all applications matching the filter.
+// Edges to and from matching applications are included, along with the vertices at the other end.
+func (g *Graph) Export(filter Filter) Export {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	vertices := make(map[string]Vertex)
+	edges := make(map[string]*Edge)
+	selected := make(map[string]bool)
+
+	edge := func(from, to Vertex, edgeType string) *Edge {
+		vertices[from.ID] = from
+		vertices[to.ID] = to
+		key := fmt.Sprintf("%s\x00%s\x00%s", from.ID, to.ID, edgeType)
+		if edges[key] == nil {
+			edges[key] = &Edge{From: from.ID, To: to.ID, Type: edgeType}
+		}
+		return edges[key]
+	}
+
+	// Edges are collected from all applications, so that rules declared by applications outside of
+	// the filter are accounted for on edges to and from selected applications.
+	for _, node := range g.nodes {
+		vertex := g.nodeVertex(node)
+		vertices[vertex.ID] = vertex
+		if filter.matches(node) {
+			selected[vertex.ID] = true
+		}
+
+		// The other side may match a rule with a wildcard rule of its own.
+		for _, rule := range node.Outbound {
+			for _, peer := range g.ruleVertices(node, rule) {
+				e := edge(vertex, peer, EdgeAccess)
+				e.Outbound = true
+				e.CrossCluster = peer.Type == TypeRemote
+				if peerNode := g.vertexNode(peer); peerNode != nil {
+					e.Inbound = e.Inbound || g.allows(peerNode, peerNode.Inbound, node.objectKey())
+				}
+			}
+		}
+		for _, rule := range node.Inbound {
+			for _, peer := range g.ruleVertices(node, rule) {
+				e := edge(peer, vertex, EdgeAccess)
+				e.Inbound = true
+				e.CrossCluster = peer.Type == TypeRemote
+				if peerNode := g.vertexNode(peer); peerNode != nil {
+					e.Outbound = e.Outbound || g.allows(peerNode, peerNode.Outbound, node.objectKey())
+				}
+			}
+		}
+		for _, ingress := range node.Ingresses {
+			edge(Vertex{ID: ingress, Type: TypeIngress, Name: ingress}, vertex, EdgeIngress)
+		}
+		for _, host := range node.ExternalHosts {
+			edge(vertex, Vertex{ID: host, Type: TypeExternal, Name: host}, EdgeExternal)
+		}
+	}
+
+	export := Export{
+		Cluster:  g.clusterName,
+		Vertices: make([]Vertex, 0),
+		Edges:    make([]Edge, 0),
+	}
+
+	included := make(map[string]bool)
+	for id := range selected {
+		included[id] = true
+	}
+	for _, e := range edges {
+		if !selected[e.From] && !selected[e.To] {
+			continue
+		}
+		included[e.From] = true
+		included[e.To] = true
+		export.Edges = append(export.Edges, *e)
+	}
+	for id := range included {
+		export.Vertices = append(export.Vertices, vertices[id])
+	}
+
+	sort.Slice(export.Vertices, func(i, j int) bool {
+		return export.Vertices[i].ID < export.Vertices[j].ID
+	})
+	sort.Slice(export.Edges, func(i, j int) bool {
+		a, b := export.Edges[i], export.Edges[j]
+		if a.From != b.From {
+			return a.From < b.From
+		}
+		if a.To != b.To {
+			return a.To < b.To
+		}
+		return a.Type < b.Type
+	})
+
+	return export
+}
+
+func (v Vertex) dotAttributes() string {
+	switch v.Type {
+	case TypeApplication:
+		return fmt.Sprintf("label=%s, shape=box", strconv.Quote(v.Namespace+"/"+v.Name))
+	case TypeNaisjob:
+		return fmt.Sprintf("label=%s, shape=box, style=rounded", strconv.Quote(v.Namespace+"/"+v.Name))
+	case TypeNamespace:
+		return fmt.Sprintf("label=%s, shape=folder", strconv.Quote(v.Namespace+"/*"))
+	case TypeRemote:
+		return fmt.Sprintf("label=%s, shape=box, style=dashed", strconv.Quote(v.ID))
+	case TypeMissing:
+		return fmt.Sprintf("label=%s, shape=box, color=red", strconv.Quote(v.Namespace+"/"+v.Name))
+	default:
+		return fmt.Sprintf("label=%s, shape=ellipse", strconv.Quote(v.Name))
+	}
+}
+
+func (e Edge) dotAttributes() string {
+	if e.Type != EdgeAccess {
+		return "color=gray"
+	}
+
+	style := "solid"
+	if e.CrossCluster {
+		style = "dashed"
+	}
+
+	// Rules to other clusters can only be verified from one side.
+	switch {
+	case e.Outbound && e.Inbound, e.CrossCluster:
+		return fmt.Sprintf("style=%s", style)
+	case e.Outbound:
+		return fmt.Sprintf("style=%s, color=red, label=\"outbound only\"", style)
+	default:
+		return fmt.Sprintf("style=%s, color=red, label=\"inbound only\"", style)
+	}
+}
+
+// WriteDOT renders the graph in the Graphviz DOT language.
+func (export Export) WriteDOT(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "digraph %s {\n\trankdir=LR;\n", strconv.Quote(export.Cluster))
+	if err != nil {
+		return err
+	}
+	for _, v := range export.Vertices {
+		_, err = fmt.Fprintf(w, "\t%s [%s];\n", strconv.Quote(v.ID), v.dotAttributes())
+		if err != nil {
+			return err
+		}
+	}
+	for _, e := range export.Edges {
+		_, err = fmt.Fprintf(w, "\t%s -> %s [%s];\n", strconv.Quote(e.From), strconv.Quote(e.To), e.dotAttributes())
+		if err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintln(w, "}")
+	return err
+}
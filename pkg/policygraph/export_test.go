@@ -0,0 +1,121 @@
+package policygraph_test
+
+import (
+	"bytes"
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/policygraph"
+	"github.com/stretchr/testify/assert"
+)
+
+func exportGraph() *policygraph.Graph {
+	graph := policygraph.New("cluster")
+	graph.Set(&policygraph.Node{
+		Type:      policygraph.TypeApplication,
+		Name:      "frontend",
+		Namespace: "a",
+		Team:      "a",
+		Ingresses: []string{"https://frontend.example.com"},
+		Outbound: []nais_io_v1.AccessPolicyRule{
+			rule("backend", "b", ""),
+			rule("remote", "c", "other-cluster"),
+		},
+	})
+	graph.Set(&policygraph.Node{
+		Type:          policygraph.TypeApplication,
+		Name:          "backend",
+		Namespace:     "b",
+		Team:          "b",
+		Inbound:       []nais_io_v1.AccessPolicyRule{rule("*", "a", "")},
+		ExternalHosts: []string{"api.example.com"},
+	})
+	graph.Set(&policygraph.Node{
+		Type:      policygraph.TypeNaisjob,
+		Name:      "job",
+		Namespace: "c",
+		Team:      "c",
+		Outbound:  []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")},
+	})
+	return graph
+}
+
+func TestExport(t *testing.T) {
+	t.Run("all applications", func(t *testing.T) {
+		export := exportGraph().Export(policygraph.Filter{})
+
+		assert.Equal(t, "cluster", export.Cluster)
+		assert.Equal(t, []policygraph.Edge{
+			{From: "cluster/a/*", To: "cluster/b/backend", Type: policygraph.EdgeAccess, Inbound: true},
+			{From: "cluster/a/frontend", To: "cluster/b/backend", Type: policygraph.EdgeAccess, Outbound: true, Inbound: true},
+			{From: "cluster/a/frontend", To: "other-cluster/c/remote", Type: policygraph.EdgeAccess, Outbound: true, CrossCluster: true},
+			{From: "cluster/b/backend", To: "api.example.com", Type: policygraph.EdgeExternal},
+			{From: "cluster/c/naisjob/job", To: "cluster/b/backend", Type: policygraph.EdgeAccess, Outbound: true},
+			{From: "https://frontend.example.com", To: "cluster/a/frontend", Type: policygraph.EdgeIngress},
+		}, export.Edges)
+		assert.Len(t, export.Vertices, 7)
+	})
+
+	t.Run("filtered by team", func(t *testing.T) {
+		export := exportGraph().Export(policygraph.Filter{Teams: []string{"c"}})
+
+		assert.Equal(t, []policygraph.Edge{
+			{From: "cluster/c/naisjob/job", To: "cluster/b/backend", Type: policygraph.EdgeAccess, Outbound: true},
+		}, export.Edges)
+		assert.Equal(t, []policygraph.Vertex{
+			{ID: "cluster/b/backend", Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Cluster: "cluster", Team: "b"},
+			{ID: "cluster/c/naisjob/job", Type: policygraph.TypeNaisjob, Name: "job", Namespace: "c", Cluster: "cluster", Team: "c"},
+		}, export.Vertices)
+	})
+
+	t.Run("filtered by namespace without matches", func(t *testing.T) {
+		export := exportGraph().Export(policygraph.Filter{Namespaces: []string{"d"}})
+		assert.Empty(t, export.Vertices)
+		assert.Empty(t, export.Edges)
+	})
+
+	t.Run("application and naisjob with the same name", func(t *testing.T) {
+		graph := exportGraph()
+		graph.Set(&policygraph.Node{
+			Type:      policygraph.TypeApplication,
+			Name:      "job",
+			Namespace: "c",
+			Team:      "c",
+			Inbound:   []nais_io_v1.AccessPolicyRule{rule("scheduler", "", "")},
+		})
+		graph.Set(&policygraph.Node{
+			Type:      policygraph.TypeApplication,
+			Name:      "scheduler",
+			Namespace: "c",
+			Team:      "c",
+			Outbound:  []nais_io_v1.AccessPolicyRule{rule("job", "", "")},
+		})
+		export := graph.Export(policygraph.Filter{Namespaces: []string{"c"}})
+
+		assert.Equal(t, []policygraph.Edge{
+			{From: "cluster/c/naisjob/job", To: "cluster/b/backend", Type: policygraph.EdgeAccess, Outbound: true},
+			{From: "cluster/c/scheduler", To: "cluster/c/job", Type: policygraph.EdgeAccess, Outbound: true, Inbound: true},
+			{From: "cluster/c/scheduler", To: "cluster/c/naisjob/job", Type: policygraph.EdgeAccess, Outbound: true},
+		}, export.Edges)
+		assert.Equal(t, []policygraph.Vertex{
+			{ID: "cluster/b/backend", Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Cluster: "cluster", Team: "b"},
+			{ID: "cluster/c/job", Type: policygraph.TypeApplication, Name: "job", Namespace: "c", Cluster: "cluster", Team: "c"},
+			{ID: "cluster/c/naisjob/job", Type: policygraph.TypeNaisjob, Name: "job", Namespace: "c", Cluster: "cluster", Team: "c"},
+			{ID: "cluster/c/scheduler", Type: policygraph.TypeApplication, Name: "scheduler", Namespace: "c", Cluster: "cluster", Team: "c"},
+		}, export.Vertices)
+	})
+
+	t.Run("graphviz output", func(t *testing.T) {
+		export := exportGraph().Export(policygraph.Filter{Namespaces: []string{"c"}})
+		buf := &bytes.Buffer{}
+		err := export.WriteDOT(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, `digraph "cluster" {
+	rankdir=LR;
+	"cluster/b/backend" [label="b/backend", shape=box];
+	"cluster/c/naisjob/job" [label="c/job", shape=box, style=rounded];
+	"cluster/c/naisjob/job" -> "cluster/b/backend" [style=solid, color=red, label="outbound only"];
+}
+`, buf.String())
+	})
+}
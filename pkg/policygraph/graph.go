@@ -0,0 +1,484 @@
+package policygraph
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Kind identifies the type of inconsistency found between access policies.
+type Kind string
+
+const (
+	// An outbound rule is not matched by an inbound rule on the receiving side.
+	KindMissingInboundRule Kind = "MissingInboundRule"
+	// An inbound rule is not matched by an outbound rule on the calling side.
+	KindMissingOutboundRule Kind = "MissingOutboundRule"
+	// An inbound rule preauthorizes a caller for TokenX or Azure AD tokens, but the caller has no outbound rule.
+	KindPreauthorizedWithoutNetworkAccess Kind = "PreauthorizedWithoutNetworkAccess"
+	// A rule refers to an application that does not exist.
+	KindApplicationNotFound Kind = "ApplicationNotFound"
+	// A rule refers to a namespace that does not exist.
+	KindNamespaceNotFound Kind = "NamespaceNotFound"
+)
+
+// Node types.
+const (
+	TypeApplication = "application"
+	TypeNaisjob     = "naisjob"
+)
+
+const wildcard = "*"
+
+// Issue is a single inconsistency, as seen from one of the applications involved.
+type Issue struct {
+	Kind    Kind   `json:"kind"`
+	Peer    string `json:"peer"`
+	Message string `json:"message"`
+}
+
+// Report lists all issues found for a single application or naisjob.
+type Report struct {
+	Type      string  `json:"type"`
+	Name      string  `json:"name"`
+	Namespace string  `json:"namespace"`
+	Issues    []Issue `json:"issues"`
+}
+
+// Key identifies an application or naisjob in the graph.
+// An application and a naisjob may have the same name, so the node type is part of the key.
+type Key struct {
+	Type      string
+	Namespace string
+	Name      string
+}
+
+// Node is the part of an application or naisjob relevant to access policies.
+type Node struct {
+	Type          string
+	Name          string
+	Namespace     string
+	Team          string
+	Inbound       []nais_io_v1.AccessPolicyRule
+	Outbound      []nais_io_v1.AccessPolicyRule
+	Ingresses     []string
+	ExternalHosts []string
+	TokenX        bool
+	Azure         bool
+}
+
+// Key returns the key identifying the node in the graph.
+func (node *Node) Key() Key {
+	return Key{Type: node.Type, Namespace: node.Namespace, Name: node.Name}
+}
+
+// objectKey returns the name access policy rules refer to the node by.
+func (node *Node) objectKey() client.ObjectKey {
+	return client.ObjectKey{Namespace: node.Namespace, Name: node.Name}
+}
+
+// Namespaces returns the namespaces in this cluster referred to by the access policy rules of the node,
+// including the namespace of the node itself.
+func (node *Node) Namespaces(clusterName string) []string {
+	seen := map[string]bool{node.Namespace: true}
+	namespaces := []string{node.Namespace}
+	for _, rules := range [][]nais_io_v1.AccessPolicyRule{node.Inbound, node.Outbound} {
+		for _, rule := range rules {
+			if !rule.MatchesCluster(clusterName) || len(rule.Namespace) == 0 || seen[rule.Namespace] {
+				continue
+			}
+			seen[rule.Namespace] = true
+			namespaces = append(namespaces, rule.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// NodeFromApplication extracts the access policy of an application.
+func NodeFromApplication(app *nais_io_v1alpha1.Application) *Node {
+	node := &Node{
+		Type:      TypeApplication,
+		Name:      app.GetName(),
+		Namespace: app.GetNamespace(),
+		Team:      app.GetLabels()["team"],
+		TokenX:    app.Spec.TokenX != nil && app.Spec.TokenX.Enabled,
+		Azure:     app.Spec.Azure != nil && app.Spec.Azure.Application != nil && app.Spec.Azure.Application.Enabled,
+	}
+	for _, ingress := range app.Spec.Ingresses {
+		node.Ingresses = append(node.Ingresses, string(ingress))
+	}
+	node.setAccessPolicy(app.Spec.AccessPolicy)
+	return node
+}
+
+// NodeFromNaisjob extracts the access policy of a naisjob.
+func NodeFromNaisjob(job *nais_io_v1.Naisjob) *Node {
+	node := &Node{
+		Type:      TypeNaisjob,
+		Name:      job.GetName(),
+		Namespace: job.GetNamespace(),
+		Team:      job.GetLabels()["team"],
+		Azure:     job.Spec.Azure != nil && job.Spec.Azure.Application != nil && job.Spec.Azure.Application.Enabled,
+	}
+	node.setAccessPolicy(job.Spec.AccessPolicy)
+	return node
+}
+
+func (node *Node) setAccessPolicy(accessPolicy *nais_io_v1.AccessPolicy) {
+	if accessPolicy == nil {
+		return
+	}
+	if accessPolicy.Inbound != nil {
+		node.Inbound = accessPolicy.Inbound.Rules.GetRules()
+	}
+	if accessPolicy.Outbound != nil {
+		node.Outbound = accessPolicy.Outbound.Rules.GetRules()
+		for _, external := range accessPolicy.Outbound.External {
+			node.ExternalHosts = append(node.ExternalHosts, external.Host)
+		}
+	}
+}
+
+// Graph keeps the access policies of all applications in the cluster in memory.
+// It is safe for concurrent use.
+type Graph struct {
+	clusterName string
+	lock        sync.RWMutex
+	nodes       map[Key]*Node
+	reported    map[Key]string
+	loaded      bool
+}
+
+func New(clusterName string) *Graph {
+	return &Graph{
+		clusterName: clusterName,
+		nodes:       make(map[Key]*Node),
+		reported:    make(map[Key]string),
+	}
+}
+
+// Load populates the graph with all applications and naisjobs in the cluster.
+// Subsequent calls are no-ops, as the graph is kept up to date with Set and Remove.
+func (g *Graph) Load(ctx context.Context, reader client.Reader) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.loaded {
+		return nil
+	}
+
+	apps := &nais_io_v1alpha1.ApplicationList{}
+	err := reader.List(ctx, apps)
+	if err != nil {
+		return fmt.Errorf("list applications: %s", err)
+	}
+
+	jobs := &nais_io_v1.NaisjobList{}
+	err = reader.List(ctx, jobs)
+	if err != nil {
+		return fmt.Errorf("list naisjobs: %s", err)
+	}
+
+	for i := range apps.Items {
+		node := NodeFromApplication(&apps.Items[i])
+		g.nodes[node.Key()] = node
+	}
+	for i := range jobs.Items {
+		node := NodeFromNaisjob(&jobs.Items[i])
+		g.nodes[node.Key()] = node
+	}
+
+	g.loaded = true
+	return nil
+}
+
+// Set adds a node to the graph, replacing any previous node with the same type and name.
+func (g *Graph) Set(node *Node) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.nodes[node.Key()] = node
+}
+
+// Remove deletes a node from the graph.
+func (g *Graph) Remove(key Key) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.nodes, key)
+	delete(g.reported, key)
+}
+
+// Changed records the issues last reported for an application, and returns true if they differ from the previous report.
+// Applications that have not been reported on before are considered to have no issues.
+func (g *Graph) Changed(key Key, issues []Issue) bool {
+	fingerprint := make([]string, len(issues))
+	for i, issue := range issues {
+		fingerprint[i] = issue.Message
+	}
+	joined := strings.Join(fingerprint, "\n")
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	previous := g.reported[key]
+	g.reported[key] = joined
+	return previous != joined
+}
+
+// Issues returns all issues concerning a single application or naisjob.
+// Only the rules of the node itself, and rules of other nodes referring to it, are checked.
+// The namespaces must include those returned by Node.Namespaces.
+func (g *Graph) Issues(key Key, namespaces map[string]bool) []Issue {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	node := g.nodes[key]
+	if node == nil {
+		return nil
+	}
+
+	issues := make(map[Key][]Issue)
+	for _, other := range g.nodes {
+		if other == node || g.refersTo(other, node) {
+			g.checkNode(other, namespaces, issues)
+		}
+	}
+
+	return sortIssues(issues[key])
+}
+
+// Reports returns the issues of all applications with at least one issue, sorted by namespace and name.
+func (g *Graph) Reports(namespaces map[string]bool) []Report {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	issues := make(map[Key][]Issue)
+	for _, node := range g.nodes {
+		g.checkNode(node, namespaces, issues)
+	}
+
+	reports := make([]Report, 0)
+	for key, list := range issues {
+		reports = append(reports, Report{
+			Type:      key.Type,
+			Name:      key.Name,
+			Namespace: key.Namespace,
+			Issues:    sortIssues(list),
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Namespace != reports[j].Namespace {
+			return reports[i].Namespace < reports[j].Namespace
+		}
+		if reports[i].Name != reports[j].Name {
+			return reports[i].Name < reports[j].Name
+		}
+		return reports[i].Type < reports[j].Type
+	})
+
+	return reports
+}
+
+// Namespaces returns the names of all namespaces in the cluster.
+// Use ExistingNamespaces when checking a single node.
+func Namespaces(ctx context.Context, reader client.Reader) (map[string]bool, error) {
+	list := &corev1.NamespaceList{}
+	err := reader.List(ctx, list)
+	if err != nil {
+		return nil, fmt.Errorf("list namespaces: %s", err)
+	}
+	namespaces := make(map[string]bool, len(list.Items))
+	for _, ns := range list.Items {
+		namespaces[ns.Name] = true
+	}
+	return namespaces, nil
+}
+
+// ExistingNamespaces looks up which of the given namespaces exist in the cluster.
+func ExistingNamespaces(ctx context.Context, reader client.Reader, names []string) (map[string]bool, error) {
+	namespaces := make(map[string]bool, len(names))
+	for _, name := range names {
+		err := reader.Get(ctx, client.ObjectKey{Name: name}, &corev1.Namespace{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("get namespace %s: %s", name, err)
+		}
+		namespaces[name] = true
+	}
+	return namespaces, nil
+}
+
+// peer resolves the application a rule refers to.
+// Rules referring to other clusters cannot be verified, and wildcard rules match everything; these return false.
+func (g *Graph) peer(node *Node, rule nais_io_v1.AccessPolicyRule) (client.ObjectKey, bool) {
+	if !rule.MatchesCluster(g.clusterName) {
+		return client.ObjectKey{}, false
+	}
+	if rule.Application == wildcard {
+		return client.ObjectKey{}, false
+	}
+	namespace := rule.Namespace
+	if len(namespace) == 0 {
+		namespace = node.Namespace
+	}
+	return client.ObjectKey{Namespace: namespace, Name: rule.Application}, true
+}
+
+// allows returns true if any of the rules, declared by node, matches the application identified by key.
+func (g *Graph) allows(node *Node, rules []nais_io_v1.AccessPolicyRule, key client.ObjectKey) bool {
+	for _, rule := range rules {
+		if !rule.MatchesCluster(g.clusterName) {
+			continue
+		}
+		namespace := rule.Namespace
+		if len(namespace) == 0 {
+			namespace = node.Namespace
+		}
+		if namespace != key.Namespace {
+			continue
+		}
+		if rule.Application == wildcard || rule.Application == key.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// named returns the application and naisjob an access policy rule referring to key matches.
+func (g *Graph) named(key client.ObjectKey) []*Node {
+	nodes := make([]*Node, 0, 2)
+	for _, nodeType := range []string{TypeApplication, TypeNaisjob} {
+		node := g.nodes[Key{Type: nodeType, Namespace: key.Namespace, Name: key.Name}]
+		if node != nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// allowedByAny returns true if any of the peers has a rule matching the application identified by key.
+func (g *Graph) allowedByAny(peers []*Node, rules func(peer *Node) []nais_io_v1.AccessPolicyRule, key client.ObjectKey) bool {
+	for _, peer := range peers {
+		if g.allows(peer, rules(peer), key) {
+			return true
+		}
+	}
+	return false
+}
+
+// refersTo returns true if any rule declared by node refers to target.
+func (g *Graph) refersTo(node, target *Node) bool {
+	for _, rules := range [][]nais_io_v1.AccessPolicyRule{node.Inbound, node.Outbound} {
+		for _, rule := range rules {
+			key, ok := g.peer(node, rule)
+			if ok && key == target.objectKey() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inbound(node *Node) []nais_io_v1.AccessPolicyRule {
+	return node.Inbound
+}
+
+func outbound(node *Node) []nais_io_v1.AccessPolicyRule {
+	return node.Outbound
+}
+
+// checkNode finds the issues with the rules declared by node, indexed by the application they concern.
+// Issues between two applications are reported on both of them.
+func (g *Graph) checkNode(node *Node, namespaces map[string]bool, issues map[Key][]Issue) {
+	add := func(key Key, kind Kind, peer client.ObjectKey, format string, args ...interface{}) {
+		issues[key] = append(issues[key], Issue{
+			Kind:    kind,
+			Peer:    peer.String(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// exists reports rules referring to missing namespaces or applications, and returns the matching peer nodes.
+	exists := func(direction string, key client.ObjectKey) []*Node {
+		if !namespaces[key.Namespace] {
+			add(node.Key(), KindNamespaceNotFound, key, "%s rule refers to namespace '%s', which does not exist", direction, key.Namespace)
+			return nil
+		}
+		peers := g.named(key)
+		if len(peers) == 0 {
+			add(node.Key(), KindApplicationNotFound, key, "%s rule refers to application '%s', which does not exist", direction, key)
+		}
+		return peers
+	}
+
+	for _, rule := range node.Outbound {
+		key, ok := g.peer(node, rule)
+		if !ok {
+			continue
+		}
+		peers := exists("outbound", key)
+		if len(peers) == 0 || g.allowedByAny(peers, inbound, node.objectKey()) {
+			continue
+		}
+		add(node.Key(), KindMissingInboundRule, key, "outbound rule to '%s' has no matching inbound rule in '%s'", key, key)
+		for _, peer := range peers {
+			add(peer.Key(), KindMissingInboundRule, node.objectKey(), "'%s' has an outbound rule to this application, but there is no inbound rule allowing it", node.objectKey())
+		}
+	}
+
+	for _, rule := range node.Inbound {
+		key, ok := g.peer(node, rule)
+		if !ok {
+			continue
+		}
+		peers := exists("inbound", key)
+		if len(peers) == 0 || g.allowedByAny(peers, outbound, node.objectKey()) {
+			continue
+		}
+		if node.TokenX || node.Azure {
+			add(node.Key(), KindPreauthorizedWithoutNetworkAccess, key, "'%s' is preauthorized for %s tokens, but has no outbound rule allowing network traffic to this application; it can only call through an ingress", key, tokenProviders(node))
+			for _, peer := range peers {
+				add(peer.Key(), KindPreauthorizedWithoutNetworkAccess, node.objectKey(), "preauthorized for %s tokens by '%s', but there is no outbound rule allowing network traffic to it", tokenProviders(node), node.objectKey())
+			}
+			continue
+		}
+		add(node.Key(), KindMissingOutboundRule, key, "inbound rule from '%s' has no matching outbound rule in '%s'", key, key)
+		for _, peer := range peers {
+			add(peer.Key(), KindMissingOutboundRule, node.objectKey(), "'%s' has an inbound rule for this application, but there is no outbound rule to it", node.objectKey())
+		}
+	}
+}
+
+func sortIssues(list []Issue) []Issue {
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Peer != list[j].Peer {
+			return list[i].Peer < list[j].Peer
+		}
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Message < list[j].Message
+	})
+	return list
+}
+
+func tokenProviders(node *Node) string {
+	providers := make([]string, 0, 2)
+	if node.TokenX {
+		providers = append(providers, "TokenX")
+	}
+	if node.Azure {
+		providers = append(providers, "Azure AD")
+	}
+	return strings.Join(providers, " and ")
+}
@@ -0,0 +1,145 @@
+package policygraph_test
+
+import (
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/policygraph"
+	"github.com/stretchr/testify/assert"
+)
+
+func rule(application, namespace, cluster string) nais_io_v1.AccessPolicyRule {
+	return nais_io_v1.AccessPolicyRule{
+		Application: application,
+		Namespace:   namespace,
+		Cluster:     cluster,
+	}
+}
+
+func kinds(issues []policygraph.Issue) []policygraph.Kind {
+	result := make([]policygraph.Kind, 0, len(issues))
+	for _, issue := range issues {
+		result = append(result, issue.Kind)
+	}
+	return result
+}
+
+func TestGraph(t *testing.T) {
+	namespaces := map[string]bool{"a": true, "b": true}
+	frontend := policygraph.Key{Type: policygraph.TypeApplication, Namespace: "a", Name: "frontend"}
+	backend := policygraph.Key{Type: policygraph.TypeApplication, Namespace: "b", Name: "backend"}
+
+	t.Run("matching rules produce no issues", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Inbound: []nais_io_v1.AccessPolicyRule{rule("frontend", "a", "cluster")}})
+		assert.Empty(t, graph.Reports(namespaces))
+	})
+
+	t.Run("wildcard inbound rules match all applications in a namespace", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Inbound: []nais_io_v1.AccessPolicyRule{rule("*", "a", "")}})
+		assert.Empty(t, graph.Reports(namespaces))
+	})
+
+	t.Run("rules for other clusters are not checked", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "c", "other-cluster")}})
+		assert.Empty(t, graph.Reports(namespaces))
+	})
+
+	t.Run("one-sided outbound rule is reported on both applications", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b"})
+
+		issues := graph.Issues(frontend, namespaces)
+		assert.Equal(t, []policygraph.Kind{policygraph.KindMissingInboundRule}, kinds(issues))
+		assert.Equal(t, "b/backend", issues[0].Peer)
+
+		issues = graph.Issues(backend, namespaces)
+		assert.Equal(t, []policygraph.Kind{policygraph.KindMissingInboundRule}, kinds(issues))
+		assert.Equal(t, "a/frontend", issues[0].Peer)
+	})
+
+	t.Run("one-sided inbound rule is reported on both applications", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a"})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Inbound: []nais_io_v1.AccessPolicyRule{rule("frontend", "a", "")}})
+
+		assert.Equal(t, []policygraph.Kind{policygraph.KindMissingOutboundRule}, kinds(graph.Issues(frontend, namespaces)))
+		assert.Equal(t, []policygraph.Kind{policygraph.KindMissingOutboundRule}, kinds(graph.Issues(backend, namespaces)))
+	})
+
+	t.Run("preauthorization without network rule", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a"})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", TokenX: true, Inbound: []nais_io_v1.AccessPolicyRule{rule("frontend", "a", "")}})
+
+		issues := graph.Issues(backend, namespaces)
+		assert.Equal(t, []policygraph.Kind{policygraph.KindPreauthorizedWithoutNetworkAccess}, kinds(issues))
+		assert.Contains(t, issues[0].Message, "TokenX")
+	})
+
+	t.Run("rules referring to missing applications and namespaces", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{
+			Type:      policygraph.TypeApplication,
+			Name:      "frontend",
+			Namespace: "a",
+			Outbound: []nais_io_v1.AccessPolicyRule{
+				rule("backend", "b", ""),
+				rule("backend", "c", ""),
+			},
+		})
+
+		reports := graph.Reports(namespaces)
+		assert.Len(t, reports, 1)
+		assert.Equal(t, "frontend", reports[0].Name)
+		assert.Equal(t, []policygraph.Kind{policygraph.KindApplicationNotFound, policygraph.KindNamespaceNotFound}, kinds(reports[0].Issues))
+	})
+
+	t.Run("removed applications are no longer part of the graph", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Inbound: []nais_io_v1.AccessPolicyRule{rule("frontend", "a", "")}})
+		graph.Remove(backend)
+
+		assert.Equal(t, []policygraph.Kind{policygraph.KindApplicationNotFound}, kinds(graph.Issues(frontend, namespaces)))
+	})
+
+	t.Run("applications and naisjobs with the same name are separate nodes", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "worker", Namespace: "a"})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeNaisjob, Name: "worker", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Inbound: []nais_io_v1.AccessPolicyRule{rule("worker", "a", "")}})
+		assert.Empty(t, graph.Reports(namespaces))
+
+		graph.Remove(policygraph.Key{Type: policygraph.TypeApplication, Namespace: "a", Name: "worker"})
+		assert.Empty(t, graph.Issues(backend, namespaces))
+
+		graph.Remove(policygraph.Key{Type: policygraph.TypeNaisjob, Namespace: "a", Name: "worker"})
+		assert.Equal(t, []policygraph.Kind{policygraph.KindApplicationNotFound}, kinds(graph.Issues(backend, namespaces)))
+	})
+
+	t.Run("issues are checked only for the edges of a single application", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "backend", Namespace: "b", Inbound: []nais_io_v1.AccessPolicyRule{rule("frontend", "a", "")}})
+		graph.Set(&policygraph.Node{Type: policygraph.TypeApplication, Name: "other", Namespace: "c", Outbound: []nais_io_v1.AccessPolicyRule{rule("missing", "d", "")}})
+
+		node := &policygraph.Node{Type: policygraph.TypeApplication, Name: "frontend", Namespace: "a", Outbound: []nais_io_v1.AccessPolicyRule{rule("backend", "b", ""), rule("remote", "e", "other-cluster")}}
+		assert.Equal(t, []string{"a", "b"}, node.Namespaces("cluster"))
+		assert.Empty(t, graph.Issues(frontend, map[string]bool{"a": true, "b": true}))
+	})
+
+	t.Run("changes in reported issues are detected", func(t *testing.T) {
+		graph := policygraph.New("cluster")
+		issues := []policygraph.Issue{{Kind: policygraph.KindApplicationNotFound, Message: "foo"}}
+		assert.False(t, graph.Changed(frontend, nil))
+		assert.True(t, graph.Changed(frontend, issues))
+		assert.False(t, graph.Changed(frontend, issues))
+		assert.True(t, graph.Changed(frontend, nil))
+	})
+}
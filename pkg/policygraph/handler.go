@@ -0,0 +1,71 @@
+package policygraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Handler serves the access policy reports of all applications as JSON.
+func Handler(graph *Graph, reader client.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := graph.Load(r.Context(), reader)
+		if err != nil {
+			log.Errorf("Access policy report: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		namespaces, err := Namespaces(r.Context(), reader)
+		if err != nil {
+			log.Errorf("Access policy report: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(w).Encode(graph.Reports(namespaces))
+		if err != nil {
+			log.Errorf("Access policy report: write response: %s", err)
+		}
+	})
+}
+
+// GraphHandler serves the access policy graph as JSON, or as Graphviz DOT with the query parameter format=dot.
+// The graph can be filtered with the query parameters namespace and team, each of which may be repeated.
+func GraphHandler(graph *Graph, reader client.Reader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		format := query.Get("format")
+		if format != "" && format != "json" && format != "dot" {
+			http.Error(w, fmt.Sprintf("unsupported format '%s'; use 'json' or 'dot'", format), http.StatusBadRequest)
+			return
+		}
+
+		err := graph.Load(r.Context(), reader)
+		if err != nil {
+			log.Errorf("Access policy graph: %s", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		export := graph.Export(Filter{
+			Namespaces: query["namespace"],
+			Teams:      query["team"],
+		})
+
+		if format == "dot" {
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+			err = export.WriteDOT(w)
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+			err = json.NewEncoder(w).Encode(export)
+		}
+		if err != nil {
+			log.Errorf("Access policy graph: write response: %s", err)
+		}
+	})
+}
@@ -17,6 +17,11 @@ func CreateJobSpec(naisjob *nais_io_v1.Naisjob, ast *resource.Ast, resourceOptio
 		return batchv1.JobSpec{}, err
 	}
 
+	err = pod.SetSecurityContext(podSpec, ast, resourceOptions, naisjob.GetName(), naisjob.GetAnnotations())
+	if err != nil {
+		return batchv1.JobSpec{}, err
+	}
+
 	jobSpec := batchv1.JobSpec{
 		ActiveDeadlineSeconds: naisjob.Spec.ActiveDeadlineSeconds,
 		BackoffLimit:          util.Int32p(naisjob.Spec.BackoffLimit),
@@ -62,6 +62,16 @@ func deploymentSpec(app *nais_io_v1alpha1.Application, ast *resource.Ast, resour
 		return nil, err
 	}
 
+	err = pod.SetTopologySpread(podSpec, resourceOptions, app.Name, app.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pod.SetSecurityContext(podSpec, ast, resourceOptions, app.Name, app.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
 	var strategy appsv1.DeploymentStrategy
 
 	if app.Spec.Strategy.Type == nais_io_v1alpha1.DeploymentStrategyRecreate {
@@ -4,10 +4,10 @@ import (
 	"fmt"
 	"strings"
 
-	google_bigquery_crd "github.com/nais/liberator/pkg/apis/bigquery.cnrm.cloud.google.com/v1beta1"
 	google_iam_crd "github.com/nais/liberator/pkg/apis/iam.cnrm.cloud.google.com/v1beta1"
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	"github.com/nais/liberator/pkg/namegen"
+	google_bigquery_crd "github.com/nais/naiserator/pkg/apis/bigquery.cnrm.cloud.google.com/v1beta1"
 	"github.com/nais/naiserator/pkg/resourcecreator/google"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	"github.com/nais/naiserator/pkg/util"
@@ -16,16 +16,36 @@ import (
 	"k8s.io/utils/pointer"
 )
 
-func CreateDataset(source resource.Source, ast *resource.Ast, resourceOptions resource.Options, bigQueryDatasets []nais_io_v1.CloudBigQueryDataset, serviceAccountName string) error {
+func CreateDataset(source resource.Source, ast *resource.Ast, resourceOptions resource.Options, bigQueryDatasets []nais_io_v1.CloudBigQueryDataset, serviceAccountName string, annotations map[string]string, inboundRules []nais_io_v1.AccessPolicyRule) error {
 	if bigQueryDatasets == nil {
 		return nil
 	}
+
+	settings, err := parseSettings(annotations, bigQueryDatasets, inboundRules, source.GetNamespace(), resourceOptions.ClusterName)
+	if err != nil {
+		return err
+	}
+
 	for _, bigQuerySpec := range bigQueryDatasets {
 		bigQueryInstance, err := createDataset(source, bigQuerySpec, resourceOptions.GoogleProjectId, serviceAccountName)
 		if err != nil {
 			return err
 		}
-		ast.AppendOperation(resource.OperationCreateIfNotExists, bigQueryInstance)
+
+		// Datasets are always updated with the complete access list,
+		// so that readers removed from the annotation lose their access to existing datasets.
+		datasetSettings, ok := settings[bigQuerySpec.Name]
+		for _, reader := range datasetSettings.Readers {
+			bigQueryInstance.Spec.Access = append(bigQueryInstance.Spec.Access, readerAccess(reader, source.GetNamespace(), resourceOptions.GoogleProjectId))
+		}
+		ast.AppendOperation(resource.OperationCreateOrUpdate, bigQueryInstance)
+
+		if ok {
+			err = createTables(source, ast, bigQueryInstance, bigQuerySpec, datasetSettings)
+			if err != nil {
+				return err
+			}
+		}
 
 		iamPolicyMember, err := iAMPolicyMember(source, bigQueryInstance, resourceOptions.GoogleProjectId, resourceOptions.GoogleTeamProjectId, serviceAccountName)
 		if err != nil {
@@ -100,3 +120,67 @@ func createDataset(source resource.Source, bigQuerySpec nais_io_v1.CloudBigQuery
 		},
 	}, nil
 }
+
+func readerAccess(reader Reader, namespace, googleProjectId string) *google_bigquery_crd.BigQueryDatasetAccess {
+	if len(reader.Group) > 0 {
+		return &google_bigquery_crd.BigQueryDatasetAccess{
+			Role:         ReaderRole,
+			GroupByEmail: reader.Group,
+		}
+	}
+	return &google_bigquery_crd.BigQueryDatasetAccess{
+		Role:        ReaderRole,
+		UserByEmail: google.GcpServiceAccountName(resource.AppNamespaceHash(reader.Application, reader.namespace(namespace)), googleProjectId),
+	}
+}
+
+func createTables(source resource.Source, ast *resource.Ast, dataset *google_bigquery_crd.BigQueryDataset, bigQuerySpec nais_io_v1.CloudBigQueryDataset, settings Settings) error {
+	datasetRef := google_bigquery_crd.DatasetRef{Name: dataset.Name}
+	baseName := fmt.Sprintf("%s-%s", source.GetName(), strings.ToLower(bigQuerySpec.Name))
+
+	for _, table := range settings.Tables {
+		spec, err := table.spec(datasetRef)
+		if err != nil {
+			return err
+		}
+		bigQueryTable, err := createTable(source, baseName, spec, bigQuerySpec.CascadingDelete)
+		if err != nil {
+			return err
+		}
+		ast.AppendOperation(resource.OperationCreateOrUpdate, bigQueryTable)
+	}
+
+	for _, view := range settings.Views {
+		bigQueryTable, err := createTable(source, baseName, view.spec(datasetRef), bigQuerySpec.CascadingDelete)
+		if err != nil {
+			return err
+		}
+		ast.AppendOperation(resource.OperationCreateOrUpdate, bigQueryTable)
+	}
+
+	return nil
+}
+
+func createTable(source resource.Source, baseName string, spec google_bigquery_crd.BigQueryTableSpec, cascadingDelete bool) (*google_bigquery_crd.BigQueryTable, error) {
+	objectMeta := resource.CreateObjectMeta(source)
+	tableName := strings.ReplaceAll(fmt.Sprintf("%s-%s", baseName, strings.ToLower(spec.ResourceID)), "_", "-")
+
+	shortName, err := namegen.ShortName(tableName, validation.DNS1035LabelMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta.Name = shortName
+
+	if !cascadingDelete {
+		util.SetAnnotation(&objectMeta, google.DeletionPolicyAnnotation, google.DeletionPolicyAbandon)
+	}
+
+	return &google_bigquery_crd.BigQueryTable{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "BigQueryTable",
+			APIVersion: google.BigQueryAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec:       spec,
+	}, nil
+}
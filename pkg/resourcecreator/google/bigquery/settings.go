@@ -0,0 +1,258 @@
+package google_bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/ghodss/yaml"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	google_bigquery_crd "github.com/nais/naiserator/pkg/apis/bigquery.cnrm.cloud.google.com/v1beta1"
+)
+
+// Applications configure the datasets declared in spec.gcp.bigQueryDatasets further with this annotation,
+// keyed by dataset name, e.g.
+//
+//	nais.io/bigquery-datasets: |
+//	  mydataset:
+//	    readers:
+//	      - application: otherapp
+//	        namespace: othernamespace
+//	      - group: analysts@example.com
+//	    tables:
+//	      - name: events
+//	        description: Events by time
+//	        schema:
+//	          - name: id
+//	            type: STRING
+//	            mode: REQUIRED
+//	          - name: created
+//	            type: TIMESTAMP
+//	        timePartitioning:
+//	          type: DAY
+//	          field: created
+//	          expirationDays: 90
+//	    views:
+//	      - name: recent_events
+//	        query: SELECT * FROM mydataset.events WHERE created > TIMESTAMP_SUB(CURRENT_TIMESTAMP(), INTERVAL 1 DAY)
+//
+// Applications given reader access must also be allowed in spec.accessPolicy.inbound.rules.
+const Annotation = "nais.io/bigquery-datasets"
+
+// Role given to readers of a dataset.
+const ReaderRole = "READER"
+
+var (
+	tableNameRegex     = regexp.MustCompile("^[a-zA-Z0-9_]+$")
+	partitioningTypes  = []string{"HOUR", "DAY", "MONTH", "YEAR"}
+	millisecondsPerDay = int64(24 * time.Hour / time.Millisecond)
+)
+
+type Reader struct {
+	Application string `json:"application,omitempty"`
+	Namespace   string `json:"namespace,omitempty"`
+	Group       string `json:"group,omitempty"`
+}
+
+type Field struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Mode        string `json:"mode,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type TimePartitioning struct {
+	Type           string `json:"type"`
+	Field          string `json:"field,omitempty"`
+	ExpirationDays int64  `json:"expirationDays,omitempty"`
+}
+
+type Table struct {
+	Name             string            `json:"name"`
+	Description      string            `json:"description,omitempty"`
+	Schema           []Field           `json:"schema,omitempty"`
+	TimePartitioning *TimePartitioning `json:"timePartitioning,omitempty"`
+	// RFC 3339 timestamp after which the table is deleted
+	ExpirationTime string `json:"expirationTime,omitempty"`
+}
+
+type View struct {
+	Name         string `json:"name"`
+	Description  string `json:"description,omitempty"`
+	Query        string `json:"query"`
+	UseLegacySql bool   `json:"useLegacySql,omitempty"`
+}
+
+type Settings struct {
+	Readers []Reader `json:"readers,omitempty"`
+	Tables  []Table  `json:"tables,omitempty"`
+	Views   []View   `json:"views,omitempty"`
+}
+
+// parseSettings reads the dataset settings from the annotations of an application.
+// Settings for datasets that are not declared in the application spec are rejected,
+// as are application readers that are not allowed by the inbound access policy.
+func parseSettings(annotations map[string]string, naisDatasets []nais_io_v1.CloudBigQueryDataset, inboundRules []nais_io_v1.AccessPolicyRule, namespace, clusterName string) (map[string]Settings, error) {
+	settings := make(map[string]Settings)
+	value, ok := annotations[Annotation]
+	if !ok {
+		return settings, nil
+	}
+
+	err := yaml.Unmarshal([]byte(value), &settings)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	declared := make(map[string]bool)
+	for _, dataset := range naisDatasets {
+		declared[dataset.Name] = true
+	}
+
+	for name, datasetSettings := range settings {
+		if !declared[name] {
+			return nil, fmt.Errorf("annotation '%s': dataset '%s' is not declared in spec.gcp.bigQueryDatasets", Annotation, name)
+		}
+		err = datasetSettings.validate(inboundRules, namespace, clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("annotation '%s': dataset '%s': %s", Annotation, name, err)
+		}
+	}
+
+	return settings, nil
+}
+
+func (s Settings) validate(inboundRules []nais_io_v1.AccessPolicyRule, namespace, clusterName string) error {
+	for _, reader := range s.Readers {
+		if (len(reader.Application) > 0) == (len(reader.Group) > 0) {
+			return fmt.Errorf("readers must have either an application or a group")
+		}
+		if len(reader.Application) > 0 && !allowed(reader, inboundRules, namespace, clusterName) {
+			return fmt.Errorf("reader '%s' in namespace '%s' is not allowed by spec.accessPolicy.inbound.rules", reader.Application, reader.namespace(namespace))
+		}
+	}
+
+	names := make(map[string]bool)
+	checkName := func(kind, name string) error {
+		if !tableNameRegex.MatchString(name) {
+			return fmt.Errorf("%s name '%s' must consist of letters, digits or '_'", kind, name)
+		}
+		if names[name] {
+			return fmt.Errorf("%s name '%s' is declared more than once", kind, name)
+		}
+		names[name] = true
+		return nil
+	}
+
+	for _, table := range s.Tables {
+		err := checkName("table", table.Name)
+		if err != nil {
+			return err
+		}
+		for _, field := range table.Schema {
+			if len(field.Name) == 0 || len(field.Type) == 0 {
+				return fmt.Errorf("table '%s': schema fields must have a name and a type", table.Name)
+			}
+		}
+		if table.TimePartitioning != nil && !contains(partitioningTypes, table.TimePartitioning.Type) {
+			return fmt.Errorf("table '%s': timePartitioning type must be one of %v", table.Name, partitioningTypes)
+		}
+		if len(table.ExpirationTime) > 0 {
+			_, err = time.Parse(time.RFC3339, table.ExpirationTime)
+			if err != nil {
+				return fmt.Errorf("table '%s': expirationTime must be an RFC 3339 timestamp: %s", table.Name, err)
+			}
+		}
+	}
+
+	for _, view := range s.Views {
+		err := checkName("view", view.Name)
+		if err != nil {
+			return err
+		}
+		if len(view.Query) == 0 {
+			return fmt.Errorf("view '%s': query is required", view.Name)
+		}
+	}
+
+	return nil
+}
+
+func (r Reader) namespace(defaultNamespace string) string {
+	if len(r.Namespace) == 0 {
+		return defaultNamespace
+	}
+	return r.Namespace
+}
+
+func allowed(reader Reader, inboundRules []nais_io_v1.AccessPolicyRule, namespace, clusterName string) bool {
+	for _, rule := range inboundRules {
+		if !rule.MatchesCluster(clusterName) {
+			continue
+		}
+		ruleNamespace := rule.Namespace
+		if len(ruleNamespace) == 0 {
+			ruleNamespace = namespace
+		}
+		if rule.Application == reader.Application && ruleNamespace == reader.namespace(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t Table) spec(datasetRef google_bigquery_crd.DatasetRef) (google_bigquery_crd.BigQueryTableSpec, error) {
+	spec := google_bigquery_crd.BigQueryTableSpec{
+		ResourceID:  t.Name,
+		DatasetRef:  datasetRef,
+		Description: t.Description,
+	}
+
+	if len(t.Schema) > 0 {
+		schema, err := json.Marshal(t.Schema)
+		if err != nil {
+			return spec, err
+		}
+		spec.Schema = string(schema)
+	}
+
+	if t.TimePartitioning != nil {
+		spec.TimePartitioning = &google_bigquery_crd.TimePartitioning{
+			Type:         t.TimePartitioning.Type,
+			Field:        t.TimePartitioning.Field,
+			ExpirationMs: t.TimePartitioning.ExpirationDays * millisecondsPerDay,
+		}
+	}
+
+	if len(t.ExpirationTime) > 0 {
+		expirationTime, err := time.Parse(time.RFC3339, t.ExpirationTime)
+		if err != nil {
+			return spec, err
+		}
+		spec.ExpirationTime = expirationTime.UnixNano() / int64(time.Millisecond)
+	}
+
+	return spec, nil
+}
+
+func (v View) spec(datasetRef google_bigquery_crd.DatasetRef) google_bigquery_crd.BigQueryTableSpec {
+	return google_bigquery_crd.BigQueryTableSpec{
+		ResourceID:  v.Name,
+		DatasetRef:  datasetRef,
+		Description: v.Description,
+		View: &google_bigquery_crd.View{
+			Query:        v.Query,
+			UseLegacySql: v.UseLegacySql,
+		},
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -4,13 +4,15 @@ import (
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	google_bigquery "github.com/nais/naiserator/pkg/resourcecreator/google/bigquery"
 	google_iam "github.com/nais/naiserator/pkg/resourcecreator/google/iam"
+	google_pubsub "github.com/nais/naiserator/pkg/resourcecreator/google/pubsub"
+	google_secretmanager "github.com/nais/naiserator/pkg/resourcecreator/google/secretmanager"
 	google_sql "github.com/nais/naiserator/pkg/resourcecreator/google/sql"
 	google_storagebucket "github.com/nais/naiserator/pkg/resourcecreator/google/storagebucket"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	v1 "k8s.io/api/core/v1"
 )
 
-func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options, naisGCP *nais_io_v1.GCP) error {
+func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options, naisGCP *nais_io_v1.GCP, naisAccessPolicy nais_io_v1.AccessPolicy) error {
 	if len(resourceOptions.GoogleProjectId) <= 0 {
 		return nil
 	}
@@ -25,9 +27,21 @@ func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.
 	ast.AppendOperation(resource.OperationCreateIfNotExists, &googleServiceAccount)
 	ast.AppendOperation(resource.OperationCreateIfNotExists, &googleServiceAccountBinding)
 
+	err := google_pubsub.Create(source, ast, resourceOptions)
+	if err != nil {
+		return err
+	}
+
 	if naisGCP != nil {
-		google_storagebucket.Create(source, ast, resourceOptions, googleServiceAccount, naisGCP.Buckets)
-		err := google_bigquery.CreateDataset(source, ast, resourceOptions, naisGCP.BigQueryDatasets, googleServiceAccount.Name)
+		err = google_storagebucket.Create(source, ast, resourceOptions, googleServiceAccount, naisGCP.Buckets, source.GetAnnotations())
+		if err != nil {
+			return err
+		}
+		var inboundRules []nais_io_v1.AccessPolicyRule
+		if naisAccessPolicy.Inbound != nil {
+			inboundRules = naisAccessPolicy.Inbound.Rules.GetRules()
+		}
+		err = google_bigquery.CreateDataset(source, ast, resourceOptions, naisGCP.BigQueryDatasets, googleServiceAccount.Name, source.GetAnnotations(), inboundRules)
 		if err != nil {
 			return err
 		}
@@ -43,3 +57,17 @@ func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.
 
 	return nil
 }
+
+// RequiresTeamProject returns true if the source requests resources that are created in its team project.
+func RequiresTeamProject(source resource.Source, naisGCP *nais_io_v1.GCP) bool {
+	if naisGCP != nil {
+		return true
+	}
+	annotations := source.GetAnnotations()
+	for _, annotation := range []string{google_pubsub.Annotation, google_secretmanager.Annotation} {
+		if _, ok := annotations[annotation]; ok {
+			return true
+		}
+	}
+	return false
+}
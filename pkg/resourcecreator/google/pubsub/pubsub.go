@@ -0,0 +1,299 @@
+package google_pubsub
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	google_iam_crd "github.com/nais/liberator/pkg/apis/iam.cnrm.cloud.google.com/v1beta1"
+	"github.com/nais/liberator/pkg/namegen"
+	google_pubsub_crd "github.com/nais/naiserator/pkg/apis/pubsub.cnrm.cloud.google.com/v1beta1"
+	"github.com/nais/naiserator/pkg/resourcecreator/google"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Applications declare Pub/Sub topics and subscriptions in their team project with this annotation, e.g.
+//
+//	nais.io/pubsub: |
+//	  topics:
+//	    - name: mytopic
+//	      messageRetentionDuration: 86400s
+//	  subscriptions:
+//	    - name: mysubscription
+//	      topic: mytopic
+//	      ackDeadlineSeconds: 20
+//	      deadLetterTopic: mytopic-dead-letter
+//	      maxDeliveryAttempts: 5
+//
+// Topics not declared by the application itself are referred to as topics in the team project.
+//
+// Pub/Sub forwards undeliverable messages to the dead letter topic with its service agent, which is granted
+// access to the topic and the subscription. The service agent is named after the team project number,
+// so dead letter topics require the nais.io/team-project-number annotation on the namespace.
+const Annotation = "nais.io/pubsub"
+
+type Topic struct {
+	Name                     string `json:"name"`
+	MessageRetentionDuration string `json:"messageRetentionDuration,omitempty"`
+	CascadingDelete          bool   `json:"cascadingDelete,omitempty"`
+}
+
+type Subscription struct {
+	Name                     string `json:"name"`
+	Topic                    string `json:"topic"`
+	AckDeadlineSeconds       int    `json:"ackDeadlineSeconds,omitempty"`
+	MessageRetentionDuration string `json:"messageRetentionDuration,omitempty"`
+	RetainAckedMessages      bool   `json:"retainAckedMessages,omitempty"`
+	DeadLetterTopic          string `json:"deadLetterTopic,omitempty"`
+	MaxDeliveryAttempts      int    `json:"maxDeliveryAttempts,omitempty"`
+	CascadingDelete          bool   `json:"cascadingDelete,omitempty"`
+}
+
+type PubSub struct {
+	Topics        []Topic        `json:"topics,omitempty"`
+	Subscriptions []Subscription `json:"subscriptions,omitempty"`
+}
+
+func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options) error {
+	value, ok := source.GetAnnotations()[Annotation]
+	if !ok {
+		return nil
+	}
+
+	pubsub := PubSub{}
+	err := yaml.Unmarshal([]byte(value), &pubsub)
+	if err != nil {
+		return fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	err = pubsub.validate()
+	if err != nil {
+		return fmt.Errorf("annotation '%s': %s", Annotation, err)
+	}
+
+	for _, t := range pubsub.Topics {
+		topic := topic(source, t)
+		ast.AppendOperation(resource.OperationCreateOrUpdate, topic)
+
+		policyMember, err := iAMPolicyMember(source, topic.Name+"-publisher", applicationMember(source, resourceOptions), "roles/pubsub.publisher", resourceRef(topic.Kind, topic.Name), resourceOptions)
+		if err != nil {
+			return err
+		}
+		ast.AppendOperation(resource.OperationCreateIfNotExists, policyMember)
+
+		ast.Env = append(ast.Env, corev1.EnvVar{
+			Name:  envVarName("PUBSUB_TOPIC", t.Name),
+			Value: t.Name,
+		})
+	}
+
+	for _, s := range pubsub.Subscriptions {
+		subscription := subscription(source, s, pubsub.topicRef(s.Topic, resourceOptions.GoogleTeamProjectId))
+		if len(s.DeadLetterTopic) > 0 {
+			subscription.Spec.DeadLetterPolicy = &google_pubsub_crd.DeadLetterPolicy{
+				DeadLetterTopicRef:  pubsub.topicRef(s.DeadLetterTopic, resourceOptions.GoogleTeamProjectId),
+				MaxDeliveryAttempts: s.MaxDeliveryAttempts,
+			}
+		}
+		ast.AppendOperation(resource.OperationCreateOrUpdate, subscription)
+
+		policyMember, err := iAMPolicyMember(source, subscription.Name+"-subscriber", applicationMember(source, resourceOptions), "roles/pubsub.subscriber", resourceRef(subscription.Kind, subscription.Name), resourceOptions)
+		if err != nil {
+			return err
+		}
+		ast.AppendOperation(resource.OperationCreateIfNotExists, policyMember)
+
+		if subscription.Spec.DeadLetterPolicy != nil {
+			err = deadLetterPolicyMembers(source, ast, subscription, resourceOptions)
+			if err != nil {
+				return fmt.Errorf("annotation '%s': subscription '%s': %s", Annotation, s.Name, err)
+			}
+		}
+
+		ast.Env = append(ast.Env, corev1.EnvVar{
+			Name:  envVarName("PUBSUB_SUBSCRIPTION", s.Name),
+			Value: s.Name,
+		})
+	}
+
+	return nil
+}
+
+func (p PubSub) validate() error {
+	names := make(map[string]bool)
+	check := func(kind, name string) error {
+		errs := validation.IsDNS1123Label(name)
+		if len(errs) > 0 {
+			return fmt.Errorf("%s name '%s': %s", kind, name, strings.Join(errs, ", "))
+		}
+		if names[name] {
+			return fmt.Errorf("%s name '%s' is declared more than once", kind, name)
+		}
+		names[name] = true
+		return nil
+	}
+
+	for _, topic := range p.Topics {
+		err := check("topic", topic.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, subscription := range p.Subscriptions {
+		err := check("subscription", subscription.Name)
+		if err != nil {
+			return err
+		}
+		if len(subscription.Topic) == 0 {
+			return fmt.Errorf("subscription '%s': topic is required", subscription.Name)
+		}
+		if subscription.AckDeadlineSeconds != 0 && (subscription.AckDeadlineSeconds < 10 || subscription.AckDeadlineSeconds > 600) {
+			return fmt.Errorf("subscription '%s': ackDeadlineSeconds must be between 10 and 600", subscription.Name)
+		}
+		if len(subscription.DeadLetterTopic) == 0 && subscription.MaxDeliveryAttempts != 0 {
+			return fmt.Errorf("subscription '%s': maxDeliveryAttempts requires a deadLetterTopic", subscription.Name)
+		}
+		if subscription.MaxDeliveryAttempts != 0 && (subscription.MaxDeliveryAttempts < 5 || subscription.MaxDeliveryAttempts > 100) {
+			return fmt.Errorf("subscription '%s': maxDeliveryAttempts must be between 5 and 100", subscription.Name)
+		}
+	}
+
+	return nil
+}
+
+// topicRef refers to topics declared by the application by name, and to all other topics by their name in the team project.
+func (p PubSub) topicRef(name, googleTeamProjectId string) google_pubsub_crd.TopicRef {
+	for _, topic := range p.Topics {
+		if topic.Name == name {
+			return google_pubsub_crd.TopicRef{Name: name}
+		}
+	}
+	return google_pubsub_crd.TopicRef{External: fmt.Sprintf("projects/%s/topics/%s", googleTeamProjectId, name)}
+}
+
+func topic(source resource.Source, t Topic) *google_pubsub_crd.PubSubTopic {
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = t.Name
+	if !t.CascadingDelete {
+		util.SetAnnotation(&objectMeta, google.DeletionPolicyAnnotation, google.DeletionPolicyAbandon)
+	}
+
+	return &google_pubsub_crd.PubSubTopic{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PubSubTopic",
+			APIVersion: google.PubSubAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: google_pubsub_crd.PubSubTopicSpec{
+			ResourceID:               t.Name,
+			MessageRetentionDuration: t.MessageRetentionDuration,
+		},
+	}
+}
+
+func subscription(source resource.Source, s Subscription, topicRef google_pubsub_crd.TopicRef) *google_pubsub_crd.PubSubSubscription {
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = s.Name
+	if !s.CascadingDelete {
+		util.SetAnnotation(&objectMeta, google.DeletionPolicyAnnotation, google.DeletionPolicyAbandon)
+	}
+
+	return &google_pubsub_crd.PubSubSubscription{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PubSubSubscription",
+			APIVersion: google.PubSubAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: google_pubsub_crd.PubSubSubscriptionSpec{
+			ResourceID:               s.Name,
+			TopicRef:                 topicRef,
+			AckDeadlineSeconds:       s.AckDeadlineSeconds,
+			MessageRetentionDuration: s.MessageRetentionDuration,
+			RetainAckedMessages:      s.RetainAckedMessages,
+		},
+	}
+}
+
+// deadLetterPolicyMembers lets the Pub/Sub service agent forward messages from the subscription to its dead letter topic.
+func deadLetterPolicyMembers(source resource.Source, ast *resource.Ast, subscription *google_pubsub_crd.PubSubSubscription, resourceOptions resource.Options) error {
+	if len(resourceOptions.GoogleTeamProjectNumber) == 0 {
+		return fmt.Errorf("deadLetterTopic requires the '%s' annotation on namespace '%s'", google.ProjectNumberAnnotation, source.GetNamespace())
+	}
+	member := serviceAgentMember(resourceOptions.GoogleTeamProjectNumber)
+
+	topicRef := subscription.Spec.DeadLetterPolicy.DeadLetterTopicRef
+	deadLetterTopic := resourceRef("PubSubTopic", topicRef.Name)
+	if len(topicRef.External) > 0 {
+		deadLetterTopic = google_iam_crd.ResourceRef{
+			ApiVersion: google.PubSubAPIVersion,
+			Kind:       "PubSubTopic",
+			External:   &topicRef.External,
+		}
+	}
+
+	publisher, err := iAMPolicyMember(source, subscription.Name+"-dead-letter-publisher", member, "roles/pubsub.publisher", deadLetterTopic, resourceOptions)
+	if err != nil {
+		return err
+	}
+	ast.AppendOperation(resource.OperationCreateIfNotExists, publisher)
+
+	subscriber, err := iAMPolicyMember(source, subscription.Name+"-dead-letter-subscriber", member, "roles/pubsub.subscriber", resourceRef(subscription.Kind, subscription.Name), resourceOptions)
+	if err != nil {
+		return err
+	}
+	ast.AppendOperation(resource.OperationCreateIfNotExists, subscriber)
+
+	return nil
+}
+
+// applicationMember is the Google service account of the application.
+func applicationMember(source resource.Source, resourceOptions resource.Options) string {
+	return fmt.Sprintf("serviceAccount:%s", google.GcpServiceAccountName(resource.CreateAppNamespaceHash(source), resourceOptions.GoogleProjectId))
+}
+
+// serviceAgentMember is the Pub/Sub service agent of the team project.
+func serviceAgentMember(projectNumber string) string {
+	return fmt.Sprintf("serviceAccount:service-%s@gcp-sa-pubsub.iam.gserviceaccount.com", projectNumber)
+}
+
+func resourceRef(kind, name string) google_iam_crd.ResourceRef {
+	return google_iam_crd.ResourceRef{
+		ApiVersion: google.PubSubAPIVersion,
+		Kind:       kind,
+		Name:       &name,
+	}
+}
+
+func iAMPolicyMember(source resource.Source, name, member, role string, ref google_iam_crd.ResourceRef, resourceOptions resource.Options) (*google_iam_crd.IAMPolicyMember, error) {
+	shortName, err := namegen.ShortName(name, validation.DNS1035LabelMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = shortName
+	policy := &google_iam_crd.IAMPolicyMember{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "IAMPolicyMember",
+			APIVersion: google.IAMAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: google_iam_crd.IAMPolicyMemberSpec{
+			Member:      member,
+			Role:        role,
+			ResourceRef: ref,
+		},
+	}
+
+	util.SetAnnotation(policy, google.ProjectIdAnnotation, resourceOptions.GoogleTeamProjectId)
+
+	return policy, nil
+}
+
+func envVarName(prefix, name string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
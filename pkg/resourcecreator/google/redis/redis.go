@@ -0,0 +1,210 @@
+package google_redis
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	google_redis_crd "github.com/nais/naiserator/pkg/apis/redis.cnrm.cloud.google.com/v1beta1"
+	"github.com/nais/naiserator/pkg/resourcecreator/google"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Applications request a Memorystore Redis instance in their team project with this annotation, e.g.
+//
+//	nais.io/redis: |
+//	  tier: STANDARD_HA
+//	  memorySizeGb: 2
+//	  redisVersion: REDIS_6_X
+//	  reservedIpRange: 10.20.30.0/29
+//
+// The instance is named after the application. Its address is injected as REDIS_HOST and REDIS_PORT
+// once Config Connector has reported it.
+const Annotation = "nais.io/redis"
+
+const (
+	TierBasic      = "BASIC"
+	TierStandardHA = "STANDARD_HA"
+	DefaultPort    = 6379
+
+	DefaultMemorySizeGb = 1
+	MaxMemorySizeGb     = 300
+)
+
+type Redis struct {
+	Tier            string `json:"tier,omitempty"`
+	MemorySizeGb    int    `json:"memorySizeGb,omitempty"`
+	RedisVersion    string `json:"redisVersion,omitempty"`
+	ReservedIpRange string `json:"reservedIpRange"`
+	CascadingDelete bool   `json:"cascadingDelete,omitempty"`
+}
+
+// Requested returns true if the source requests a Redis instance.
+func Requested(source resource.Source) bool {
+	_, ok := source.GetAnnotations()[Annotation]
+	return ok
+}
+
+// InstanceName returns the name of the RedisInstance resource of the source.
+func InstanceName(source resource.Source) string {
+	return source.GetName()
+}
+
+func parse(source resource.Source) (*Redis, error) {
+	value, ok := source.GetAnnotations()[Annotation]
+	if !ok {
+		return nil, nil
+	}
+
+	redis := &Redis{}
+	err := yaml.Unmarshal([]byte(value), redis)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	err = redis.validate()
+	if err != nil {
+		return nil, fmt.Errorf("annotation '%s': %s", Annotation, err)
+	}
+
+	return redis, nil
+}
+
+func (r *Redis) validate() error {
+	switch r.Tier {
+	case "", TierBasic, TierStandardHA:
+	default:
+		return fmt.Errorf("tier must be either '%s' or '%s'", TierBasic, TierStandardHA)
+	}
+
+	if r.MemorySizeGb < 0 || r.MemorySizeGb > MaxMemorySizeGb {
+		return fmt.Errorf("memorySizeGb must be between 1 and %d, or 0 for the default of %d", MaxMemorySizeGb, DefaultMemorySizeGb)
+	}
+
+	_, _, err := net.ParseCIDR(r.ReservedIpRange)
+	if err != nil {
+		return fmt.Errorf("reservedIpRange must be a CIDR: %s", err)
+	}
+
+	return nil
+}
+
+func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options) error {
+	redis, err := parse(source)
+	if err != nil || redis == nil {
+		return err
+	}
+
+	if len(resourceOptions.GoogleProjectId) == 0 {
+		return fmt.Errorf("annotation '%s' is not supported in this cluster", Annotation)
+	}
+
+	ast.AppendOperation(resource.OperationCreateOrUpdate, instance(source, *redis))
+
+	if resourceOptions.NetworkPolicy {
+		ast.AppendOperation(resource.OperationCreateOrUpdate, networkPolicy(source, redis.ReservedIpRange))
+	}
+
+	if len(resourceOptions.RedisHost) > 0 {
+		port := resourceOptions.RedisPort
+		if port == 0 {
+			port = DefaultPort
+		}
+		ast.Env = append(ast.Env, []corev1.EnvVar{
+			{
+				Name:  "REDIS_HOST",
+				Value: resourceOptions.RedisHost,
+			},
+			{
+				Name:  "REDIS_PORT",
+				Value: strconv.Itoa(port),
+			},
+		}...)
+	}
+
+	return nil
+}
+
+func instance(source resource.Source, redis Redis) *google_redis_crd.RedisInstance {
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = InstanceName(source)
+
+	if !redis.CascadingDelete {
+		util.SetAnnotation(&objectMeta, google.DeletionPolicyAnnotation, google.DeletionPolicyAbandon)
+	}
+
+	tier := redis.Tier
+	if len(tier) == 0 {
+		tier = TierBasic
+	}
+
+	memorySizeGb := redis.MemorySizeGb
+	if memorySizeGb == 0 {
+		memorySizeGb = DefaultMemorySizeGb
+	}
+
+	return &google_redis_crd.RedisInstance{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "RedisInstance",
+			APIVersion: google.RedisAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: google_redis_crd.RedisInstanceSpec{
+			ResourceID:      objectMeta.Name,
+			Region:          google.Region,
+			Tier:            tier,
+			MemorySizeGb:    memorySizeGb,
+			RedisVersion:    redis.RedisVersion,
+			ReservedIpRange: redis.ReservedIpRange,
+		},
+	}
+}
+
+// networkPolicy allows egress to the IP range of the instance, which is not covered by the default egress rules.
+func networkPolicy(source resource.Source, reservedIpRange string) *networkingv1.NetworkPolicy {
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = fmt.Sprintf("%s-redis", source.GetName())
+	port := intstr.FromInt(DefaultPort)
+	protocol := corev1.ProtocolTCP
+
+	return &networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: objectMeta,
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": source.GetName(),
+				},
+			},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeEgress,
+			},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{
+					To: []networkingv1.NetworkPolicyPeer{
+						{
+							IPBlock: &networkingv1.IPBlock{
+								CIDR: reservedIpRange,
+							},
+						},
+					},
+					Ports: []networkingv1.NetworkPolicyPort{
+						{
+							Protocol: &protocol,
+							Port:     &port,
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,239 @@
+package google_secretmanager
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	google_iam_crd "github.com/nais/liberator/pkg/apis/iam.cnrm.cloud.google.com/v1beta1"
+	"github.com/nais/liberator/pkg/namegen"
+	secretstore "github.com/nais/naiserator/pkg/apis/secrets-store.csi.x-k8s.io/v1alpha1"
+	"github.com/nais/naiserator/pkg/resourcecreator/google"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/utils/pointer"
+)
+
+// Applications reference secrets in the Secret Manager of their team project with this annotation, e.g.
+//
+//	nais.io/secret-manager: |
+//	  - name: my-secret
+//	    version: "3"
+//	    env: MY_SECRET
+//
+// Each secret is mounted as a file named after the secret in MountPath.
+// Secrets with an environment variable name are also synced to a Kubernetes secret and exposed in the environment.
+const Annotation = "nais.io/secret-manager"
+
+const (
+	MountPath             = "/var/run/secrets/nais.io/secret-manager"
+	Driver                = "secrets-store.csi.k8s.io"
+	APIVersion            = "secrets-store.csi.x-k8s.io/v1alpha1"
+	SecretAPIKind         = "SecretManagerSecret"
+	SecretAPIGroupVersion = "secretmanager.cnrm.cloud.google.com/v1beta1"
+	defaultVersion        = "latest"
+	volumeName            = "secret-manager"
+)
+
+// Secret names in Secret Manager are limited to letters, digits, dashes and underscores.
+var secretNameRegex = regexp.MustCompile("^[a-zA-Z0-9_-]{1,255}$")
+
+type Secret struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Env     string `json:"env,omitempty"`
+}
+
+// parameter is the format of the secrets expected by the GCP provider of the CSI secret store driver.
+type parameter struct {
+	ResourceName string `json:"resourceName"`
+	FileName     string `json:"fileName"`
+}
+
+func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options) error {
+	value, ok := source.GetAnnotations()[Annotation]
+	if !ok {
+		return nil
+	}
+
+	if !resourceOptions.SecretManagerEnabled || len(resourceOptions.GoogleProjectId) == 0 {
+		return fmt.Errorf("annotation '%s' is not supported in this cluster", Annotation)
+	}
+
+	secrets := make([]Secret, 0)
+	err := yaml.Unmarshal([]byte(value), &secrets)
+	if err != nil {
+		return fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	err = validate(secrets)
+	if err != nil {
+		return fmt.Errorf("annotation '%s': %s", Annotation, err)
+	}
+
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	providerClass, err := secretProviderClass(source, secrets, resourceOptions.GoogleTeamProjectId)
+	if err != nil {
+		return err
+	}
+	ast.AppendOperation(resource.OperationCreateOrUpdate, providerClass)
+
+	for _, secret := range secrets {
+		policyMember, err := iAMPolicyMember(source, secret.Name, resourceOptions)
+		if err != nil {
+			return err
+		}
+		ast.AppendOperation(resource.OperationCreateIfNotExists, policyMember)
+
+		if len(secret.Env) > 0 {
+			ast.Env = append(ast.Env, corev1.EnvVar{
+				Name: secret.Env,
+				ValueFrom: &corev1.EnvVarSource{
+					SecretKeyRef: &corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: providerClass.Name,
+						},
+						Key: secret.Name,
+					},
+				},
+			})
+		}
+	}
+
+	ast.Volumes = append(ast.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			CSI: &corev1.CSIVolumeSource{
+				Driver:   Driver,
+				ReadOnly: pointer.BoolPtr(true),
+				VolumeAttributes: map[string]string{
+					"secretProviderClass": providerClass.Name,
+				},
+			},
+		},
+	})
+	ast.VolumeMounts = append(ast.VolumeMounts, corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: MountPath,
+		ReadOnly:  true,
+	})
+
+	return nil
+}
+
+func validate(secrets []Secret) error {
+	names := make(map[string]bool)
+	for _, secret := range secrets {
+		if !secretNameRegex.MatchString(secret.Name) {
+			return fmt.Errorf("secret name '%s' must consist of alphanumeric characters, '-' or '_'", secret.Name)
+		}
+		if names[secret.Name] {
+			return fmt.Errorf("secret '%s' is referenced more than once", secret.Name)
+		}
+		names[secret.Name] = true
+
+		if len(secret.Env) > 0 {
+			errs := validation.IsEnvVarName(secret.Env)
+			if len(errs) > 0 {
+				return fmt.Errorf("secret '%s': env '%s': %s", secret.Name, secret.Env, strings.Join(errs, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func (s Secret) version() string {
+	if len(s.Version) == 0 {
+		return defaultVersion
+	}
+	return s.Version
+}
+
+func secretProviderClass(source resource.Source, secrets []Secret, googleTeamProjectId string) (*secretstore.SecretProviderClass, error) {
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = fmt.Sprintf("%s-secret-manager", source.GetName())
+
+	parameters := make([]parameter, 0, len(secrets))
+	syncedData := make([]secretstore.SecretObjectData, 0)
+	for _, secret := range secrets {
+		parameters = append(parameters, parameter{
+			ResourceName: fmt.Sprintf("projects/%s/secrets/%s/versions/%s", googleTeamProjectId, secret.Name, secret.version()),
+			FileName:     secret.Name,
+		})
+		if len(secret.Env) > 0 {
+			syncedData = append(syncedData, secretstore.SecretObjectData{
+				ObjectName: secret.Name,
+				Key:        secret.Name,
+			})
+		}
+	}
+
+	secretsParameter, err := yaml.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	providerClass := &secretstore.SecretProviderClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "SecretProviderClass",
+			APIVersion: APIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: secretstore.SecretProviderClassSpec{
+			Provider: "gcp",
+			Parameters: map[string]string{
+				"secrets": string(secretsParameter),
+			},
+		},
+	}
+
+	// The Kubernetes secret is kept in sync by the driver as long as a pod mounts the volume.
+	if len(syncedData) > 0 {
+		providerClass.Spec.SecretObjects = []secretstore.SecretObject{
+			{
+				SecretName: objectMeta.Name,
+				Type:       string(corev1.SecretTypeOpaque),
+				Data:       syncedData,
+			},
+		}
+	}
+
+	return providerClass, nil
+}
+
+func iAMPolicyMember(source resource.Source, secretName string, resourceOptions resource.Options) (*google_iam_crd.IAMPolicyMember, error) {
+	shortName, err := namegen.ShortName(fmt.Sprintf("%s-%s-secret-accessor", source.GetName(), strings.ReplaceAll(strings.ToLower(secretName), "_", "-")), validation.DNS1035LabelMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	externalName := fmt.Sprintf("projects/%s/secrets/%s", resourceOptions.GoogleTeamProjectId, secretName)
+	objectMeta := resource.CreateObjectMeta(source)
+	objectMeta.Name = shortName
+	policy := &google_iam_crd.IAMPolicyMember{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "IAMPolicyMember",
+			APIVersion: google.IAMAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: google_iam_crd.IAMPolicyMemberSpec{
+			Member: fmt.Sprintf("serviceAccount:%s", google.GcpServiceAccountName(resource.CreateAppNamespaceHash(source), resourceOptions.GoogleProjectId)),
+			Role:   "roles/secretmanager.secretAccessor",
+			ResourceRef: google_iam_crd.ResourceRef{
+				ApiVersion: SecretAPIGroupVersion,
+				Kind:       SecretAPIKind,
+				External:   &externalName,
+			},
+		},
+	}
+
+	util.SetAnnotation(policy, google.ProjectIdAnnotation, resourceOptions.GoogleTeamProjectId)
+
+	return policy, nil
+}
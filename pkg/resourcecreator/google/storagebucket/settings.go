@@ -0,0 +1,209 @@
+package google_storagebucket
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	nais "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	google_storage_crd "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
+)
+
+// Applications configure the buckets declared in spec.gcp.buckets further with this annotation, keyed by bucket name, e.g.
+//
+//	nais.io/storage-buckets: |
+//	  mybucket:
+//	    versioning: true
+//	    uniformBucketLevelAccess: true
+//	    publicAccessPrevention: enforced
+//	    role: objectAdmin
+//	    cors:
+//	      - origins: ["https://myapp.nav.no"]
+//	        methods: [GET, PUT]
+//	        responseHeaders: [Content-Type]
+//	        maxAgeSeconds: 3600
+//	    lifecycleRules:
+//	      - action:
+//	          type: SetStorageClass
+//	          storageClass: COLDLINE
+//	        condition:
+//	          age: 30
+//
+// Lifecycle rules are added after the rule from spec.gcp.buckets[].lifecycleCondition.
+const Annotation = "nais.io/storage-buckets"
+
+// Roles the application can be granted on its buckets; objectViewer is the default.
+const (
+	RoleObjectViewer = "objectViewer"
+	RoleObjectAdmin  = "objectAdmin"
+)
+
+// Lifecycle actions supported by Cloud Storage.
+const (
+	ActionDelete                         = "Delete"
+	ActionSetStorageClass                = "SetStorageClass"
+	ActionAbortIncompleteMultipartUpload = "AbortIncompleteMultipartUpload"
+)
+
+// Values for publicAccessPrevention; inherited is the default.
+const (
+	PublicAccessPreventionEnforced  = "enforced"
+	PublicAccessPreventionInherited = "inherited"
+)
+
+var storageClasses = []string{"STANDARD", "NEARLINE", "COLDLINE", "ARCHIVE"}
+
+type Cors struct {
+	Origins         []string `json:"origins"`
+	Methods         []string `json:"methods"`
+	ResponseHeaders []string `json:"responseHeaders,omitempty"`
+	MaxAgeSeconds   int      `json:"maxAgeSeconds,omitempty"`
+}
+
+type LifecycleAction struct {
+	Type         string `json:"type"`
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+type LifecycleRule struct {
+	Action    LifecycleAction         `json:"action"`
+	Condition nais.LifecycleCondition `json:"condition"`
+}
+
+type Settings struct {
+	Versioning               bool            `json:"versioning,omitempty"`
+	UniformBucketLevelAccess bool            `json:"uniformBucketLevelAccess,omitempty"`
+	PublicAccessPrevention   string          `json:"publicAccessPrevention,omitempty"`
+	Role                     string          `json:"role,omitempty"`
+	Cors                     []Cors          `json:"cors,omitempty"`
+	LifecycleRules           []LifecycleRule `json:"lifecycleRules,omitempty"`
+}
+
+// parseSettings reads the bucket settings from the annotations of an application.
+// Settings for buckets that are not declared in the application spec are rejected.
+func parseSettings(annotations map[string]string, naisBuckets []nais.CloudStorageBucket) (map[string]Settings, error) {
+	settings := make(map[string]Settings)
+	value, ok := annotations[Annotation]
+	if !ok {
+		return settings, nil
+	}
+
+	err := yaml.Unmarshal([]byte(value), &settings)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	declared := make(map[string]bool)
+	for _, bucket := range naisBuckets {
+		declared[bucket.Name] = true
+	}
+
+	for name, bucketSettings := range settings {
+		if !declared[name] {
+			return nil, fmt.Errorf("annotation '%s': bucket '%s' is not declared in spec.gcp.buckets", Annotation, name)
+		}
+		err = bucketSettings.validate()
+		if err != nil {
+			return nil, fmt.Errorf("annotation '%s': bucket '%s': %s", Annotation, name, err)
+		}
+	}
+
+	return settings, nil
+}
+
+func (s Settings) validate() error {
+	switch s.PublicAccessPrevention {
+	case "", PublicAccessPreventionEnforced, PublicAccessPreventionInherited:
+	default:
+		return fmt.Errorf("publicAccessPrevention must be either 'enforced' or 'inherited'")
+	}
+
+	switch s.Role {
+	case "", RoleObjectViewer, RoleObjectAdmin:
+	default:
+		return fmt.Errorf("role must be either '%s' or '%s'", RoleObjectViewer, RoleObjectAdmin)
+	}
+
+	for i, cors := range s.Cors {
+		if len(cors.Origins) == 0 || len(cors.Methods) == 0 {
+			return fmt.Errorf("cors rule %d: origins and methods are required", i+1)
+		}
+		if cors.MaxAgeSeconds < 0 {
+			return fmt.Errorf("cors rule %d: maxAgeSeconds cannot be negative", i+1)
+		}
+	}
+
+	for i, rule := range s.LifecycleRules {
+		switch rule.Action.Type {
+		case ActionDelete, ActionAbortIncompleteMultipartUpload:
+			if len(rule.Action.StorageClass) > 0 {
+				return fmt.Errorf("lifecycle rule %d: storageClass can only be set for the %s action", i+1, ActionSetStorageClass)
+			}
+		case ActionSetStorageClass:
+			if !contains(storageClasses, rule.Action.StorageClass) {
+				return fmt.Errorf("lifecycle rule %d: storageClass must be one of %v", i+1, storageClasses)
+			}
+		default:
+			return fmt.Errorf("lifecycle rule %d: action type must be one of '%s', '%s' or '%s'", i+1, ActionDelete, ActionSetStorageClass, ActionAbortIncompleteMultipartUpload)
+		}
+	}
+
+	return nil
+}
+
+// role returns the role granted to the application on the bucket.
+func (s Settings) role() string {
+	if len(s.Role) == 0 {
+		return RoleObjectViewer
+	}
+	return s.Role
+}
+
+// apply writes the settings to the bucket spec. Settings that are not set are written with their default values,
+// so that they are reset on buckets where they were previously set.
+func (s Settings) apply(spec *google_storage_crd.StorageBucketSpec) {
+	spec.Versioning = &google_storage_crd.Versioning{Enabled: s.Versioning}
+	spec.UniformBucketLevelAccess = s.UniformBucketLevelAccess
+	spec.PublicAccessPrevention = s.PublicAccessPrevention
+	if len(spec.PublicAccessPrevention) == 0 {
+		spec.PublicAccessPrevention = PublicAccessPreventionInherited
+	}
+	if spec.Cors == nil {
+		spec.Cors = make([]google_storage_crd.Cors, 0)
+	}
+	if spec.LifecycleRules == nil {
+		spec.LifecycleRules = make([]google_storage_crd.LifecycleRules, 0)
+	}
+
+	for _, cors := range s.Cors {
+		spec.Cors = append(spec.Cors, google_storage_crd.Cors{
+			Origin:         cors.Origins,
+			Method:         cors.Methods,
+			ResponseHeader: cors.ResponseHeaders,
+			MaxAgeSeconds:  cors.MaxAgeSeconds,
+		})
+	}
+
+	for _, rule := range s.LifecycleRules {
+		spec.LifecycleRules = append(spec.LifecycleRules, google_storage_crd.LifecycleRules{
+			Action: google_storage_crd.Action{
+				Type:         rule.Action.Type,
+				StorageClass: rule.Action.StorageClass,
+			},
+			Condition: google_storage_crd.Condition{
+				Age:              rule.Condition.Age,
+				CreatedBefore:    rule.Condition.CreatedBefore,
+				NumNewerVersions: rule.Condition.NumNewerVersions,
+				WithState:        rule.Condition.WithState,
+			},
+		})
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
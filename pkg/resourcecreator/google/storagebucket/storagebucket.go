@@ -10,7 +10,7 @@ import (
 
 	google_iam_crd "github.com/nais/liberator/pkg/apis/iam.cnrm.cloud.google.com/v1beta1"
 	nais "github.com/nais/liberator/pkg/apis/nais.io/v1"
-	google_storage_crd "github.com/nais/liberator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
+	google_storage_crd "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -51,9 +51,12 @@ func CreateBucket(objectMeta metav1.ObjectMeta, bucket nais.CloudStorageBucket)
 	}
 }
 
-func iAMPolicyMember(source resource.Source, bucket *google_storage_crd.StorageBucket, googleProjectId, googleTeamProjectId string) *google_iam_crd.IAMPolicyMember {
+func iAMPolicyMember(source resource.Source, bucket *google_storage_crd.StorageBucket, role, googleProjectId, googleTeamProjectId string) *google_iam_crd.IAMPolicyMember {
 	objectMeta := resource.CreateObjectMeta(source)
 	policyMemberName := fmt.Sprintf("%s-object-viewer", bucket.Name)
+	if role == RoleObjectAdmin {
+		policyMemberName = fmt.Sprintf("%s-object-admin", bucket.Name)
+	}
 	objectMeta.Name = policyMemberName
 	policy := &google_iam_crd.IAMPolicyMember{
 		ObjectMeta: objectMeta,
@@ -63,7 +66,7 @@ func iAMPolicyMember(source resource.Source, bucket *google_storage_crd.StorageB
 		},
 		Spec: google_iam_crd.IAMPolicyMemberSpec{
 			Member: fmt.Sprintf("serviceAccount:%s", google.GcpServiceAccountName(resource.CreateAppNamespaceHash(source), googleProjectId)),
-			Role:   "roles/storage.objectViewer",
+			Role:   "roles/storage." + role,
 			ResourceRef: google_iam_crd.ResourceRef{
 				ApiVersion: bucket.APIVersion,
 				Kind:       bucket.Kind,
@@ -77,19 +80,26 @@ func iAMPolicyMember(source resource.Source, bucket *google_storage_crd.StorageB
 	return policy
 }
 
-func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options, googleServiceAccount google_iam_crd.IAMServiceAccount, naisBucket []nais.CloudStorageBucket) {
-	if naisBucket == nil {
-		return
+func Create(source resource.Source, ast *resource.Ast, resourceOptions resource.Options, googleServiceAccount google_iam_crd.IAMServiceAccount, naisBucket []nais.CloudStorageBucket, annotations map[string]string) error {
+	settings, err := parseSettings(annotations, naisBucket)
+	if err != nil {
+		return err
 	}
 
 	for _, b := range naisBucket {
 		bucket := CreateBucket(resource.CreateObjectMeta(source), b)
-		ast.AppendOperation(resource.OperationCreateIfNotExists, bucket)
+		// Buckets are always updated with every setting spelled out,
+		// so that settings removed from the annotation are reset on existing buckets.
+		bucketSettings := settings[b.Name]
+		bucketSettings.apply(&bucket.Spec)
+		ast.AppendOperation(resource.OperationCreateOrUpdate, bucket)
 
 		bucketAccessControl := AccessControl(resource.CreateObjectMeta(source), bucket.Name, resourceOptions.GoogleProjectId, googleServiceAccount.Name)
 		ast.AppendOperation(resource.OperationCreateOrUpdate, bucketAccessControl)
 
-		iamPolicyMember := iAMPolicyMember(source, bucket, resourceOptions.GoogleProjectId, resourceOptions.GoogleTeamProjectId)
+		iamPolicyMember := iAMPolicyMember(source, bucket, bucketSettings.role(), resourceOptions.GoogleProjectId, resourceOptions.GoogleTeamProjectId)
 		ast.AppendOperation(resource.OperationCreateIfNotExists, iamPolicyMember)
 	}
+
+	return nil
 }
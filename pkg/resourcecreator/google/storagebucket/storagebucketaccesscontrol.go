@@ -3,7 +3,7 @@ package google_storagebucket
 import (
 	"fmt"
 
-	google_storage_crd "github.com/nais/liberator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
+	google_storage_crd "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
 	"github.com/nais/naiserator/pkg/resourcecreator/google"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
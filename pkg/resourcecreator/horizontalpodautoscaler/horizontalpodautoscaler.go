@@ -1,6 +1,8 @@
 package horizontalpodautoscaler
 
 import (
+	"fmt"
+
 	nais "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	"github.com/nais/naiserator/pkg/util"
@@ -8,7 +10,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-func Create(source resource.Source, ast *resource.Ast, naisReplicas nais.Replicas) {
+func Create(source resource.Source, ast *resource.Ast, workloadKind string, naisReplicas nais.Replicas, naisAnnotations map[string]string) error {
+	metrics, err := Metrics(naisAnnotations)
+	if err != nil {
+		return fmt.Errorf("create horizontal pod autoscaler: %s", err)
+	}
+
 	hpa := &v2beta2.HorizontalPodAutoscaler{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "HorizontalPodAutoscaler",
@@ -18,7 +25,7 @@ func Create(source resource.Source, ast *resource.Ast, naisReplicas nais.Replica
 		Spec: v2beta2.HorizontalPodAutoscalerSpec{
 			ScaleTargetRef: v2beta2.CrossVersionObjectReference{
 				APIVersion: "apps/v1",
-				Kind:       "Deployment",
+				Kind:       workloadKind,
 				Name:       source.GetName(),
 			},
 			Metrics: []v2beta2.MetricSpec{
@@ -38,5 +45,8 @@ func Create(source resource.Source, ast *resource.Ast, naisReplicas nais.Replica
 		},
 	}
 
+	hpa.Spec.Metrics = append(hpa.Spec.Metrics, metrics...)
+
 	ast.AppendOperation(resource.OperationCreateOrUpdate, hpa)
+	return nil
 }
@@ -0,0 +1,228 @@
+package horizontalpodautoscaler
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/naiserator/pkg/util"
+	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Metrics in addition to CPU utilization are specified as a YAML list in this annotation, e.g.
+//
+//	nais.io/autoscaling-metrics: |
+//	  - type: memory
+//	    averageUtilization: 80
+//	  - type: pods
+//	    name: http_requests_per_second
+//	    averageValue: "100"
+//	  - type: object
+//	    name: requests_per_second
+//	    object:
+//	      apiVersion: networking.k8s.io/v1beta1
+//	      kind: Ingress
+//	      name: myapp
+//	    value: 2k
+//	  - type: external
+//	    name: kafka_consumergroup_lag
+//	    selector:
+//	      topic: myteam.mytopic
+//	    averageValue: "500"
+//
+// CPU utilization is always configured with replicas.cpuThresholdPercentage.
+const MetricsAnnotation = "nais.io/autoscaling-metrics"
+
+// Metric types supported in the annotation.
+const (
+	MetricMemory   = "memory"
+	MetricPods     = "pods"
+	MetricObject   = "object"
+	MetricExternal = "external"
+)
+
+type Metric struct {
+	Type               string                               `json:"type"`
+	Name               string                               `json:"name,omitempty"`
+	Selector           map[string]string                    `json:"selector,omitempty"`
+	Object             *v2beta2.CrossVersionObjectReference `json:"object,omitempty"`
+	AverageUtilization *int                                 `json:"averageUtilization,omitempty"`
+	AverageValue       string                               `json:"averageValue,omitempty"`
+	Value              string                               `json:"value,omitempty"`
+}
+
+// target converts the target of a metric, accepting only the target types listed.
+func (m Metric) target(allowed ...v2beta2.MetricTargetType) (v2beta2.MetricTarget, error) {
+	targets := make([]v2beta2.MetricTarget, 0, 1)
+
+	if m.AverageUtilization != nil {
+		if *m.AverageUtilization < 1 {
+			return v2beta2.MetricTarget{}, fmt.Errorf("averageUtilization must be a positive percentage")
+		}
+		targets = append(targets, v2beta2.MetricTarget{
+			Type:               v2beta2.UtilizationMetricType,
+			AverageUtilization: util.Int32p(int32(*m.AverageUtilization)),
+		})
+	}
+
+	for _, value := range []struct {
+		targetType v2beta2.MetricTargetType
+		value      string
+	}{
+		{v2beta2.AverageValueMetricType, m.AverageValue},
+		{v2beta2.ValueMetricType, m.Value},
+	} {
+		if len(value.value) == 0 {
+			continue
+		}
+		quantity, err := k8sresource.ParseQuantity(value.value)
+		if err != nil {
+			return v2beta2.MetricTarget{}, fmt.Errorf("%s '%s': %s", value.targetType, value.value, err)
+		}
+		target := v2beta2.MetricTarget{Type: value.targetType}
+		if value.targetType == v2beta2.AverageValueMetricType {
+			target.AverageValue = &quantity
+		} else {
+			target.Value = &quantity
+		}
+		targets = append(targets, target)
+	}
+
+	if len(targets) != 1 {
+		return v2beta2.MetricTarget{}, fmt.Errorf("exactly one target must be set, one of %v", allowed)
+	}
+	for _, targetType := range allowed {
+		if targets[0].Type == targetType {
+			return targets[0], nil
+		}
+	}
+	return v2beta2.MetricTarget{}, fmt.Errorf("target must be one of %v", allowed)
+}
+
+func (m Metric) identifier() (v2beta2.MetricIdentifier, error) {
+	if len(m.Name) == 0 {
+		return v2beta2.MetricIdentifier{}, fmt.Errorf("metric name is required")
+	}
+	identifier := v2beta2.MetricIdentifier{Name: m.Name}
+	if len(m.Selector) > 0 {
+		identifier.Selector = &metav1.LabelSelector{MatchLabels: m.Selector}
+	}
+	return identifier, nil
+}
+
+// Spec validates the metric and converts it to a HorizontalPodAutoscaler metric.
+func (m Metric) Spec() (*v2beta2.MetricSpec, error) {
+	switch m.Type {
+	case MetricMemory:
+		if len(m.Name) > 0 || len(m.Selector) > 0 || m.Object != nil {
+			return nil, fmt.Errorf("memory metrics only take a target")
+		}
+		target, err := m.target(v2beta2.UtilizationMetricType, v2beta2.AverageValueMetricType)
+		if err != nil {
+			return nil, err
+		}
+		return &v2beta2.MetricSpec{
+			Type: v2beta2.ResourceMetricSourceType,
+			Resource: &v2beta2.ResourceMetricSource{
+				Name:   corev1.ResourceMemory,
+				Target: target,
+			},
+		}, nil
+
+	case MetricPods:
+		identifier, err := m.identifier()
+		if err != nil {
+			return nil, err
+		}
+		target, err := m.target(v2beta2.AverageValueMetricType)
+		if err != nil {
+			return nil, err
+		}
+		return &v2beta2.MetricSpec{
+			Type: v2beta2.PodsMetricSourceType,
+			Pods: &v2beta2.PodsMetricSource{
+				Metric: identifier,
+				Target: target,
+			},
+		}, nil
+
+	case MetricObject:
+		identifier, err := m.identifier()
+		if err != nil {
+			return nil, err
+		}
+		if m.Object == nil || len(m.Object.Kind) == 0 || len(m.Object.Name) == 0 {
+			return nil, fmt.Errorf("object metrics require the kind and name of the described object")
+		}
+		target, err := m.target(v2beta2.ValueMetricType, v2beta2.AverageValueMetricType)
+		if err != nil {
+			return nil, err
+		}
+		return &v2beta2.MetricSpec{
+			Type: v2beta2.ObjectMetricSourceType,
+			Object: &v2beta2.ObjectMetricSource{
+				DescribedObject: *m.Object,
+				Metric:          identifier,
+				Target:          target,
+			},
+		}, nil
+
+	case MetricExternal:
+		identifier, err := m.identifier()
+		if err != nil {
+			return nil, err
+		}
+		target, err := m.target(v2beta2.ValueMetricType, v2beta2.AverageValueMetricType)
+		if err != nil {
+			return nil, err
+		}
+		return &v2beta2.MetricSpec{
+			Type: v2beta2.ExternalMetricSourceType,
+			External: &v2beta2.ExternalMetricSource{
+				Metric: identifier,
+				Target: target,
+			},
+		}, nil
+
+	case "cpu":
+		return nil, fmt.Errorf("cpu utilization is configured with replicas.cpuThresholdPercentage")
+
+	default:
+		return nil, fmt.Errorf("unsupported metric type '%s'; use one of '%s', '%s', '%s' or '%s'", m.Type, MetricMemory, MetricPods, MetricObject, MetricExternal)
+	}
+}
+
+// Metrics parses and validates the additional metrics in the annotations of an application.
+func Metrics(annotations map[string]string) ([]v2beta2.MetricSpec, error) {
+	value, ok := annotations[MetricsAnnotation]
+	if !ok {
+		return nil, nil
+	}
+
+	metrics := make([]Metric, 0)
+	err := yaml.Unmarshal([]byte(value), &metrics)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", MetricsAnnotation, err)
+	}
+
+	specs := make([]v2beta2.MetricSpec, 0, len(metrics))
+	seen := make(map[string]bool)
+	for i, metric := range metrics {
+		spec, err := metric.Spec()
+		if err != nil {
+			return nil, fmt.Errorf("metric %d: %s", i+1, err)
+		}
+
+		key := fmt.Sprintf("%s %s %v", metric.Type, metric.Name, metric.Selector)
+		if seen[key] {
+			return nil, fmt.Errorf("metric %d: conflicts with an earlier %s metric", i+1, metric.Type)
+		}
+		seen[key] = true
+
+		specs = append(specs, *spec)
+	}
+
+	return specs, nil
+}
@@ -0,0 +1,49 @@
+package horizontalpodautoscaler_test
+
+import (
+	"testing"
+
+	"github.com/nais/naiserator/pkg/resourcecreator/horizontalpodautoscaler"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("no annotation", func(t *testing.T) {
+		metrics, err := horizontalpodautoscaler.Metrics(map[string]string{})
+		assert.NoError(t, err)
+		assert.Empty(t, metrics)
+	})
+
+	t.Run("memory average value", func(t *testing.T) {
+		metrics, err := horizontalpodautoscaler.Metrics(map[string]string{
+			horizontalpodautoscaler.MetricsAnnotation: `[{type: memory, averageValue: 512Mi}]`,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, metrics, 1)
+		assert.Equal(t, v2beta2.AverageValueMetricType, metrics[0].Resource.Target.Type)
+		assert.Equal(t, "512Mi", metrics[0].Resource.Target.AverageValue.String())
+	})
+
+	for _, test := range []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"cpu", "[{type: cpu, averageUtilization: 50}]", "metric 1: cpu utilization is configured with replicas.cpuThresholdPercentage"},
+		{"unknown type", "[{type: gpu}]", "metric 1: unsupported metric type 'gpu'; use one of 'memory', 'pods', 'object' or 'external'"},
+		{"two targets", "[{type: memory, averageUtilization: 50, averageValue: 1Gi}]", "metric 1: exactly one target must be set, one of [Utilization AverageValue]"},
+		{"wrong target type", "[{type: pods, name: rps, value: '10'}]", "metric 1: target must be one of [AverageValue]"},
+		{"invalid quantity", "[{type: external, name: lag, value: lots}]", "metric 1: Value 'lots': quantities must match the regular expression '^([+-]?[0-9.]+)([eEinumkKMGTP]*[-+]?[0-9]*)$'"},
+		{"missing name", "[{type: pods, averageValue: '10'}]", "metric 1: metric name is required"},
+		{"object without kind", "[{type: object, name: rps, object: {name: myapp}, value: '10'}]", "metric 1: object metrics require the kind and name of the described object"},
+		{"duplicate memory", "[{type: memory, averageUtilization: 50}, {type: memory, averageValue: 1Gi}]", "metric 2: conflicts with an earlier memory metric"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := horizontalpodautoscaler.Metrics(map[string]string{
+				horizontalpodautoscaler.MetricsAnnotation: test.value,
+			})
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
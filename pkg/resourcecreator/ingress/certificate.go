@@ -0,0 +1,96 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nais/liberator/pkg/namegen"
+	cert_manager_io_v1 "github.com/nais/naiserator/pkg/apis/cert-manager.io/v1"
+	gateway_networking_k8s_io_v1alpha2 "github.com/nais/naiserator/pkg/apis/gateway.networking.k8s.io/v1alpha2"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func certificateName(source resource.Source, host string) (string, error) {
+	baseName := fmt.Sprintf("%s-%s", source.GetName(), strings.ReplaceAll(host, ".", "-"))
+	return namegen.ShortName(baseName, validation.DNS1035LabelMaxLength)
+}
+
+// addTLS configures TLS termination for a custom domain, using the secret populated by cert-manager.
+func addTLS(source resource.Source, ingress *networkingv1.Ingress, host string) error {
+	for _, tls := range ingress.Spec.TLS {
+		for _, h := range tls.Hosts {
+			if h == host {
+				return nil
+			}
+		}
+	}
+
+	secretName, err := certificateName(source, host)
+	if err != nil {
+		return err
+	}
+
+	ingress.Spec.TLS = append(ingress.Spec.TLS, networkingv1.IngressTLS{
+		Hosts:      []string{host},
+		SecretName: secretName,
+	})
+	return nil
+}
+
+// certificate creates a cert-manager Certificate for custom domains, stored in the secret of the same name.
+func certificate(options resource.Options, objectMeta metav1.ObjectMeta, hosts []string) *cert_manager_io_v1.Certificate {
+	return &cert_manager_io_v1.Certificate{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Certificate",
+			APIVersion: cert_manager_io_v1.GroupVersion.String(),
+		},
+		ObjectMeta: objectMeta,
+		Spec: cert_manager_io_v1.CertificateSpec{
+			SecretName: objectMeta.Name,
+			DNSNames:   hosts,
+			IssuerRef: cert_manager_io_v1.ObjectReference{
+				Name:  options.CertManager.ClusterIssuer,
+				Kind:  cert_manager_io_v1.ClusterIssuerKind,
+				Group: cert_manager_io_v1.GroupVersion.Group,
+			},
+		},
+	}
+}
+
+// certificates creates one cert-manager Certificate for every TLS section in an ingress.
+func certificates(source resource.Source, options resource.Options, ingress *networkingv1.Ingress) []*cert_manager_io_v1.Certificate {
+	certs := make([]*cert_manager_io_v1.Certificate, 0, len(ingress.Spec.TLS))
+
+	for _, tls := range ingress.Spec.TLS {
+		objectMeta := resource.CreateObjectMeta(source)
+		objectMeta.Name = tls.SecretName
+		certs = append(certs, certificate(options, objectMeta, tls.Hosts))
+	}
+
+	return certs
+}
+
+// routeCertificates creates one cert-manager Certificate for every custom domain in an HTTPRoute.
+// The secret is referenced by the HTTPS listener of the Gateway serving custom domains.
+func routeCertificates(source resource.Source, options resource.Options, route *gateway_networking_k8s_io_v1alpha2.HTTPRoute) ([]*cert_manager_io_v1.Certificate, error) {
+	certs := make([]*cert_manager_io_v1.Certificate, 0)
+
+	for _, hostname := range route.Spec.Hostnames {
+		host := string(hostname)
+		if !options.CustomDomain(host) {
+			continue
+		}
+		name, err := certificateName(source, host)
+		if err != nil {
+			return nil, err
+		}
+		objectMeta := resource.CreateObjectMeta(source)
+		objectMeta.Name = name
+		certs = append(certs, certificate(options, objectMeta, []string{host}))
+	}
+
+	return certs, nil
+}
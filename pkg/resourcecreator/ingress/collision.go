@@ -0,0 +1,161 @@
+package ingress
+
+import (
+	"fmt"
+	"strings"
+
+	gateway_networking_k8s_io_v1alpha2 "github.com/nais/naiserator/pkg/apis/gateway.networking.k8s.io/v1alpha2"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Applications can explicitly take over host and path rules owned by other applications
+// by listing them in this annotation as a comma separated list of "namespace/application".
+const TakeoverAnnotation = "nais.io/ingress-takeover"
+
+// Route is a host and path combination served by an Ingress or HTTPRoute.
+type Route struct {
+	Host string
+	Path string
+}
+
+func (r Route) String() string {
+	return fmt.Sprintf("https://%s%s", r.Host, r.Path)
+}
+
+// Collision is a route that is served by another application.
+type Collision struct {
+	Route       Route
+	Application string
+	Namespace   string
+}
+
+func (c Collision) Owner() string {
+	return fmt.Sprintf("%s/%s", c.Namespace, c.Application)
+}
+
+func (c Collision) Error() string {
+	return fmt.Sprintf("ingress '%s' is already in use by application '%s' in namespace '%s'; if this is a planned migration, set the annotation '%s: %s'", c.Route, c.Application, c.Namespace, TakeoverAnnotation, c.Owner())
+}
+
+// nginx ingresses use regular expressions for paths, and Gateway API uses path prefixes.
+// Normalize both to the path the user specified.
+func normalizePath(path string) string {
+	path = strings.TrimSuffix(path, regexSuffix)
+	if len(path) == 0 {
+		return "/"
+	}
+	return path
+}
+
+// Routes returns all host and path combinations served by an Ingress or HTTPRoute object.
+// Other object types yield no routes.
+func Routes(obj runtime.Object) []Route {
+	routes := make([]Route, 0)
+
+	switch typed := obj.(type) {
+	case *networkingv1.Ingress:
+		for _, rule := range typed.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				routes = append(routes, Route{Host: rule.Host, Path: normalizePath(path.Path)})
+			}
+		}
+	case *networkingv1beta1.Ingress:
+		for _, rule := range typed.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				routes = append(routes, Route{Host: rule.Host, Path: normalizePath(path.Path)})
+			}
+		}
+	case *gateway_networking_k8s_io_v1alpha2.HTTPRoute:
+		for _, hostname := range typed.Spec.Hostnames {
+			for _, rule := range typed.Spec.Rules {
+				for _, match := range rule.Matches {
+					path := "/"
+					if match.Path != nil && match.Path.Value != nil {
+						path = normalizePath(*match.Path.Value)
+					}
+					routes = append(routes, Route{Host: string(hostname), Path: path})
+				}
+			}
+		}
+	}
+
+	return routes
+}
+
+// applicationOwner returns the name of the Application owning an object, if any.
+func applicationOwner(obj runtime.Object) (string, string, bool) {
+	m, err := meta.Accessor(obj)
+	if err != nil {
+		return "", "", false
+	}
+	for _, ref := range m.GetOwnerReferences() {
+		if ref.Kind == "Application" {
+			return ref.Name, m.GetNamespace(), true
+		}
+	}
+	return "", "", false
+}
+
+func takeovers(source resource.Source) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, owner := range strings.Split(source.GetAnnotations()[TakeoverAnnotation], ",") {
+		owner = strings.TrimSpace(owner)
+		if len(owner) > 0 {
+			allowed[owner] = true
+		}
+	}
+	return allowed
+}
+
+// Collisions finds routes in the desired objects that are already served by objects belonging to other Applications.
+// Existing objects not owned by an Application are not considered, and neither are
+// collisions with applications listed in the takeover annotation of the source.
+func Collisions(source resource.Source, desired []runtime.Object, existing []runtime.Object) []Collision {
+	wanted := make(map[Route]bool)
+	for _, obj := range desired {
+		for _, route := range Routes(obj) {
+			wanted[route] = true
+		}
+	}
+
+	allowed := takeovers(source)
+	collisions := make([]Collision, 0)
+	seen := make(map[Collision]bool)
+
+	for _, obj := range existing {
+		application, namespace, ok := applicationOwner(obj)
+		if !ok {
+			continue
+		}
+		if application == source.GetName() && namespace == source.GetNamespace() {
+			continue
+		}
+		for _, route := range Routes(obj) {
+			if !wanted[route] {
+				continue
+			}
+			collision := Collision{
+				Route:       route,
+				Application: application,
+				Namespace:   namespace,
+			}
+			if allowed[collision.Owner()] || seen[collision] {
+				continue
+			}
+			seen[collision] = true
+			collisions = append(collisions, collision)
+		}
+	}
+
+	return collisions
+}
@@ -0,0 +1,87 @@
+package ingress_test
+
+import (
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/ingress"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/test/fixtures"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func renderIngresses(t *testing.T, name, namespace string, options resource.Options, ingresses ...nais_io_v1.Ingress) []runtime.Object {
+	app := fixtures.MinimalApplication()
+	app.SetName(name)
+	app.SetNamespace(namespace)
+	app.Spec.Ingresses = ingresses
+	err := app.ApplyDefaults()
+	assert.NoError(t, err)
+
+	ast := resource.NewAst()
+	err = ingress.Create(app, ast, options, app.Spec.Ingresses, app.Spec.Liveness.Path, app.Spec.Service.Protocol, app.Annotations)
+	assert.NoError(t, err)
+
+	objects := make([]runtime.Object, len(ast.Operations))
+	for i := range ast.Operations {
+		objects[i] = ast.Operations[i].Resource
+	}
+	return objects
+}
+
+func TestCollisions(t *testing.T) {
+	options := resource.NewOptions()
+	options.Linkerd = true
+	options.GatewayMappings = []config.GatewayMapping{
+		{DomainSuffix: ".bar", IngressClass: "nginx"},
+	}
+
+	existing := renderIngresses(t, "otherapp", "otherteam", options, "https://foo.bar/api", "https://baz.bar")
+
+	t.Run("overlapping host and path is reported", func(t *testing.T) {
+		app := fixtures.MinimalApplication()
+		desired := renderIngresses(t, app.GetName(), app.GetNamespace(), options, "https://foo.bar/api/", "https://foo.bar")
+
+		collisions := ingress.Collisions(app, desired, existing)
+		assert.Len(t, collisions, 1)
+		assert.Equal(t, "otherapp", collisions[0].Application)
+		assert.Equal(t, "otherteam", collisions[0].Namespace)
+		assert.Equal(t, "https://foo.bar/api", collisions[0].Route.String())
+		assert.Contains(t, collisions[0].Error(), "nais.io/ingress-takeover: otherteam/otherapp")
+	})
+
+	t.Run("the application's own ingresses do not collide", func(t *testing.T) {
+		desired := renderIngresses(t, "otherapp", "otherteam", options, "https://foo.bar/api")
+		app := fixtures.MinimalApplication()
+		app.SetName("otherapp")
+		app.SetNamespace("otherteam")
+
+		assert.Empty(t, ingress.Collisions(app, desired, existing))
+	})
+
+	t.Run("takeover annotation allows overlapping rules", func(t *testing.T) {
+		app := fixtures.MinimalApplication()
+		app.SetAnnotations(map[string]string{
+			ingress.TakeoverAnnotation: "someteam/someapp, otherteam/otherapp",
+		})
+		desired := renderIngresses(t, app.GetName(), app.GetNamespace(), options, "https://foo.bar/api")
+
+		assert.Empty(t, ingress.Collisions(app, desired, existing))
+	})
+
+	t.Run("collisions are detected across ingress and gateway routes", func(t *testing.T) {
+		gatewayOptions := options
+		gatewayOptions.GatewayAPI = true
+		gatewayOptions.GatewayMappings = []config.GatewayMapping{
+			{DomainSuffix: ".bar", Gateway: config.Gateway{Name: "gw"}},
+		}
+		app := fixtures.MinimalApplication()
+		desired := renderIngresses(t, app.GetName(), app.GetNamespace(), gatewayOptions, "https://baz.bar/")
+
+		collisions := ingress.Collisions(app, desired, existing)
+		assert.Len(t, collisions, 1)
+		assert.Equal(t, "https://baz.bar/", collisions[0].Route.String())
+	})
+}
@@ -0,0 +1,138 @@
+package ingress
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	"github.com/nais/liberator/pkg/namegen"
+	gateway_networking_k8s_io_v1alpha2 "github.com/nais/naiserator/pkg/apis/gateway.networking.k8s.io/v1alpha2"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const httpRouteAPIVersion = "gateway.networking.k8s.io/v1alpha2"
+
+func httpRouteRule(appName, path string) gateway_networking_k8s_io_v1alpha2.HTTPRouteRule {
+	pathType := gateway_networking_k8s_io_v1alpha2.PathMatchPathPrefix
+	port := gateway_networking_k8s_io_v1alpha2.PortNumber(nais_io_v1alpha1.DefaultServicePort)
+
+	return gateway_networking_k8s_io_v1alpha2.HTTPRouteRule{
+		Matches: []gateway_networking_k8s_io_v1alpha2.HTTPRouteMatch{
+			{
+				Path: &gateway_networking_k8s_io_v1alpha2.HTTPPathMatch{
+					Type:  &pathType,
+					Value: &path,
+				},
+			},
+		},
+		BackendRefs: []gateway_networking_k8s_io_v1alpha2.HTTPBackendRef{
+			{
+				BackendRef: gateway_networking_k8s_io_v1alpha2.BackendRef{
+					BackendObjectReference: gateway_networking_k8s_io_v1alpha2.BackendObjectReference{
+						Name: appName,
+						Port: &port,
+					},
+				},
+			},
+		},
+	}
+}
+
+func createHTTPRouteBase(source resource.Source, host string, gateway config.Gateway) (*gateway_networking_k8s_io_v1alpha2.HTTPRoute, error) {
+	var err error
+
+	objectMeta := resource.CreateObjectMeta(source)
+
+	baseName := fmt.Sprintf("%s-%s", source.GetName(), strings.ReplaceAll(host, ".", "-"))
+	objectMeta.Name, err = namegen.ShortName(baseName, validation.DNS1035LabelMaxLength)
+	if err != nil {
+		return nil, err
+	}
+
+	parentRef := gateway_networking_k8s_io_v1alpha2.ParentReference{
+		Name: gateway.Name,
+	}
+	if len(gateway.Namespace) > 0 {
+		namespace := gateway.Namespace
+		parentRef.Namespace = &namespace
+	}
+
+	return &gateway_networking_k8s_io_v1alpha2.HTTPRoute{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "HTTPRoute",
+			APIVersion: httpRouteAPIVersion,
+		},
+		ObjectMeta: objectMeta,
+		Spec: gateway_networking_k8s_io_v1alpha2.HTTPRouteSpec{
+			ParentRefs: []gateway_networking_k8s_io_v1alpha2.ParentReference{parentRef},
+			Hostnames:  []gateway_networking_k8s_io_v1alpha2.Hostname{gateway_networking_k8s_io_v1alpha2.Hostname(host)},
+		},
+	}, nil
+}
+
+// httpRoutes creates one HTTPRoute per ingress host, attached to the Gateway mapped to the host's domain.
+// Paths are matched by prefix, which is equivalent to the regular expression used for nginx ingresses.
+func httpRoutes(source resource.Source, options resource.Options, naisIngresses []nais_io_v1.Ingress) ([]*gateway_networking_k8s_io_v1alpha2.HTTPRoute, error) {
+	routes := make([]*gateway_networking_k8s_io_v1alpha2.HTTPRoute, 0)
+	routesByHost := make(map[string]*gateway_networking_k8s_io_v1alpha2.HTTPRoute)
+
+	for _, ingress := range naisIngresses {
+		parsedUrl, err := url.Parse(string(ingress))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse URL '%s': %s", ingress, err)
+		}
+
+		if len(parsedUrl.Path) > 1 {
+			err = util.ValidateUrl(parsedUrl)
+			if err != nil {
+				return nil, err
+			}
+			parsedUrl.Path = strings.TrimRight(parsedUrl.Path, "/")
+		} else {
+			parsedUrl.Path = "/"
+		}
+
+		gateway, _ := options.ResolveGateway(parsedUrl.Host)
+		if gateway == nil {
+			return nil, fmt.Errorf("domain '%s' is not supported", parsedUrl.Host)
+		}
+
+		route := routesByHost[parsedUrl.Host]
+		if route == nil {
+			route, err = createHTTPRouteBase(source, parsedUrl.Host, *gateway)
+			if err != nil {
+				return nil, err
+			}
+			routesByHost[parsedUrl.Host] = route
+			routes = append(routes, route)
+		}
+		route.Spec.Rules = append(route.Spec.Rules, httpRouteRule(source.GetName(), parsedUrl.Path))
+	}
+
+	return routes, nil
+}
+
+func gatewayRoutes(source resource.Source, ast *resource.Ast, options resource.Options, naisIngresses []nais_io_v1.Ingress) error {
+	routes, err := httpRoutes(source, options, naisIngresses)
+	if err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		ast.AppendOperation(resource.OperationCreateOrUpdate, route)
+		certs, err := routeCertificates(source, options, route)
+		if err != nil {
+			return err
+		}
+		for _, cert := range certs {
+			ast.AppendOperation(resource.OperationCreateOrUpdate, cert)
+		}
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package ingress
 import (
 	"fmt"
 	"net/url"
+	"regexp"
 	"strings"
 
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
@@ -10,25 +11,33 @@ import (
 	"github.com/nais/liberator/pkg/namegen"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	"github.com/nais/naiserator/pkg/util"
-	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const regexSuffix = "(/.*)?"
 
-func ingressRule(appName string, u *url.URL) networkingv1beta1.IngressRule {
-	return networkingv1beta1.IngressRule{
+// Paths are matched the same way as before the path type was introduced,
+// so that switching API versions does not change which requests are routed to the application.
+var pathType = networkingv1.PathTypeImplementationSpecific
+
+func ingressRule(appName string, u *url.URL) networkingv1.IngressRule {
+	return networkingv1.IngressRule{
 		Host: u.Host,
-		IngressRuleValue: networkingv1beta1.IngressRuleValue{
-			HTTP: &networkingv1beta1.HTTPIngressRuleValue{
-				Paths: []networkingv1beta1.HTTPIngressPath{
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{
 					{
-						Path: u.Path,
-						Backend: networkingv1beta1.IngressBackend{
-							ServiceName: appName,
-							ServicePort: intstr.IntOrString{IntVal: nais_io_v1alpha1.DefaultServicePort},
+						Path:     u.Path,
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: appName,
+								Port: networkingv1.ServiceBackendPort{
+									Number: nais_io_v1alpha1.DefaultServicePort,
+								},
+							},
 						},
 					},
 				},
@@ -37,8 +46,8 @@ func ingressRule(appName string, u *url.URL) networkingv1beta1.IngressRule {
 	}
 }
 
-func ingressRules(source resource.Source, naisIngresses []nais_io_v1.Ingress) ([]networkingv1beta1.IngressRule, error) {
-	var rules []networkingv1beta1.IngressRule
+func ingressRules(source resource.Source, naisIngresses []nais_io_v1.Ingress) ([]networkingv1.IngressRule, error) {
+	var rules []networkingv1.IngressRule
 
 	for _, ingress := range naisIngresses {
 		parsedUrl, err := url.Parse(string(ingress))
@@ -59,59 +68,54 @@ func ingressRules(source resource.Source, naisIngresses []nais_io_v1.Ingress) ([
 	return rules, nil
 }
 
-func ingressRulesNginx(source resource.Source, naisIngresses []nais_io_v1.Ingress) ([]networkingv1beta1.IngressRule, error) {
-	var rules []networkingv1beta1.IngressRule
+func ingressRuleNginx(source resource.Source, ingress nais_io_v1.Ingress) (*networkingv1.IngressRule, error) {
+	parsedUrl, err := url.Parse(string(ingress))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL '%s': %s", ingress, err)
+	}
 
-	for _, ingress := range naisIngresses {
-		parsedUrl, err := url.Parse(string(ingress))
+	if len(parsedUrl.Path) > 1 {
+		err = util.ValidateUrl(parsedUrl)
 		if err != nil {
-			return nil, fmt.Errorf("failed to parse URL '%s': %s", ingress, err)
-		}
-
-		if len(parsedUrl.Path) > 1 {
-			err = util.ValidateUrl(parsedUrl)
-			if err != nil {
-				return nil, err
-			}
-			parsedUrl.Path = strings.TrimRight(parsedUrl.Path, "/") + regexSuffix
-		} else {
-			parsedUrl.Path = "/"
+			return nil, err
 		}
-
-		rules = append(rules, ingressRule(source.GetName(), parsedUrl))
+		parsedUrl.Path = strings.TrimRight(parsedUrl.Path, "/") + regexSuffix
+	} else {
+		parsedUrl.Path = "/"
 	}
 
-	return rules, nil
+	rule := ingressRule(source.GetName(), parsedUrl)
+	return &rule, nil
 }
 
 func copyNginxAnnotations(dst, src map[string]string) {
 	for k, v := range src {
-		if strings.HasPrefix(k, "nginx.ingress.kubernetes.io/") {
+		if strings.HasPrefix(k, nginxAnnotationPrefix) {
 			dst[k] = v
 		}
 	}
 }
 
-func createIngressBase(source resource.Source, rules []networkingv1beta1.IngressRule, livenessPath string) *networkingv1beta1.Ingress {
+func createIngressBase(source resource.Source, rules []networkingv1.IngressRule, livenessPath string) *networkingv1.Ingress {
 	objectMeta := resource.CreateObjectMeta(source)
 	objectMeta.Annotations["prometheus.io/scrape"] = "true"
 	objectMeta.Annotations["prometheus.io/path"] = livenessPath
 
-	return &networkingv1beta1.Ingress{
+	return &networkingv1.Ingress{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Ingress",
-			APIVersion: "networking.k8s.io/v1beta1",
+			APIVersion: "networking.k8s.io/v1",
 		},
 		ObjectMeta: objectMeta,
-		Spec: networkingv1beta1.IngressSpec{
+		Spec: networkingv1.IngressSpec{
 			Rules: rules,
 		},
 	}
 }
 
-func createIngressBaseNginx(source resource.Source, ingressClass, livenessPath, serviceProtocol string, naisAnnotations map[string]string) (*networkingv1beta1.Ingress, error) {
+func createIngressBaseNginx(source resource.Source, ingressClass, livenessPath, serviceProtocol string, naisAnnotations map[string]string) (*networkingv1.Ingress, error) {
 	var err error
-	ingress := createIngressBase(source, []networkingv1beta1.IngressRule{}, livenessPath)
+	ingress := createIngressBase(source, []networkingv1.IngressRule{}, livenessPath)
 	baseName := fmt.Sprintf("%s-%s", source.GetName(), ingressClass)
 	ingress.Name, err = namegen.ShortName(baseName, validation.DNS1035LabelMaxLength)
 	if err != nil {
@@ -120,7 +124,7 @@ func createIngressBaseNginx(source resource.Source, ingressClass, livenessPath,
 
 	copyNginxAnnotations(ingress.Annotations, naisAnnotations)
 
-	ingress.Annotations["kubernetes.io/ingress.class"] = ingressClass
+	ingress.Spec.IngressClassName = &ingressClass
 	ingress.Annotations["nginx.ingress.kubernetes.io/use-regex"] = "true"
 	ingress.Annotations["nginx.ingress.kubernetes.io/backend-protocol"] = backendProtocol(serviceProtocol)
 	return ingress, nil
@@ -139,57 +143,141 @@ func backendProtocol(portName string) string {
 	}
 }
 
-func nginxIngresses(source resource.Source, options resource.Options, naisIngresses []nais_io_v1.Ingress, livenessPath, serviceProtocol string, naisAnnotations map[string]string) ([]*networkingv1beta1.Ingress, error) {
-	rules, err := ingressRulesNginx(source, naisIngresses)
+// nginxIngresses groups ingress rules into one Ingress object per ingress class.
+// Ingresses with traffic controls get an Ingress object of their own, as nginx annotations apply to the whole object.
+func nginxIngresses(source resource.Source, options resource.Options, naisIngresses []nais_io_v1.Ingress, livenessPath, serviceProtocol string, naisAnnotations map[string]string) ([]*networkingv1.Ingress, error) {
+	// Ingress objects must have at least one path rule to be valid.
+	if len(naisIngresses) == 0 {
+		return nil, nil
+	}
+
+	err := validateNginxAnnotations(naisAnnotations, options.AllowedNginxAnnotations)
 	if err != nil {
 		return nil, err
 	}
 
-	// Ingress objects must have at least one path rule to be valid.
-	if len(rules) == 0 {
-		return nil, nil
+	controls, err := TrafficControls(naisAnnotations, naisIngresses)
+	if err != nil {
+		return nil, err
 	}
 
-	ingresses := make(map[string]*networkingv1beta1.Ingress)
+	ingresses := make(map[string]*networkingv1.Ingress)
+	ingressList := make([]*networkingv1.Ingress, 0)
 
-	for _, rule := range rules {
-		ingressClass := util.ResolveIngressClass(rule.Host, options.GatewayMappings)
+	for _, naisIngress := range naisIngresses {
+		rule, err := ingressRuleNginx(source, naisIngress)
+		if err != nil {
+			return nil, err
+		}
+
+		ingressClass, customDomain := options.ResolveIngressClass(rule.Host)
 		if ingressClass == nil {
 			return nil, fmt.Errorf("domain '%s' is not supported", rule.Host)
 		}
-		ingress := ingresses[*ingressClass]
+
+		key := *ingressClass
+		control, hasControl := controls[naisIngress]
+		if hasControl {
+			key = fmt.Sprintf("%s %s", *ingressClass, naisIngress)
+		}
+
+		ingress := ingresses[key]
 		if ingress == nil {
 			ingress, err = createIngressBaseNginx(source, *ingressClass, livenessPath, serviceProtocol, naisAnnotations)
 			if err != nil {
 				return nil, err
 			}
-			ingresses[*ingressClass] = ingress
+			if hasControl {
+				ingress.Name, err = namegen.ShortName(fmt.Sprintf("%s-%s-%s", source.GetName(), *ingressClass, nameFromURL(naisIngress)), validation.DNS1035LabelMaxLength)
+				if err != nil {
+					return nil, err
+				}
+				for k, v := range control.NginxAnnotations() {
+					ingress.Annotations[k] = v
+				}
+			}
+			ingresses[key] = ingress
+			ingressList = append(ingressList, ingress)
+		}
+		ingress.Spec.Rules = append(ingress.Spec.Rules, *rule)
+		if customDomain {
+			err = addTLS(source, ingress, rule.Host)
+			if err != nil {
+				return nil, err
+			}
 		}
-		ingress.Spec.Rules = append(ingress.Spec.Rules, rule)
-	}
 
-	ingressList := make([]*networkingv1beta1.Ingress, 0, len(ingresses))
-	for _, ingress := range ingresses {
-		ingressList = append(ingressList, ingress)
+		for _, from := range control.RedirectFrom {
+			redirect, err := redirectIngress(source, options, from, naisIngress, livenessPath, serviceProtocol, naisAnnotations)
+			if err != nil {
+				return nil, err
+			}
+			ingressList = append(ingressList, redirect)
+		}
 	}
+
 	return ingressList, nil
 }
 
+// redirectIngress creates an Ingress object that permanently redirects all requests for an alternate host to an ingress.
+func redirectIngress(source resource.Source, options resource.Options, from, to nais_io_v1.Ingress, livenessPath, serviceProtocol string, naisAnnotations map[string]string) (*networkingv1.Ingress, error) {
+	rule, err := ingressRuleNginx(source, from)
+	if err != nil {
+		return nil, err
+	}
+
+	ingressClass, customDomain := options.ResolveIngressClass(rule.Host)
+	if ingressClass == nil {
+		return nil, fmt.Errorf("domain '%s' is not supported", rule.Host)
+	}
+
+	ingress, err := createIngressBaseNginx(source, *ingressClass, livenessPath, serviceProtocol, naisAnnotations)
+	if err != nil {
+		return nil, err
+	}
+	ingress.Name, err = namegen.ShortName(fmt.Sprintf("%s-redirect-%s", source.GetName(), nameFromURL(from)), validation.DNS1035LabelMaxLength)
+	if err != nil {
+		return nil, err
+	}
+	ingress.Annotations[nginxAnnotationPrefix+"permanent-redirect"] = string(to)
+	ingress.Spec.Rules = append(ingress.Spec.Rules, *rule)
+
+	if customDomain {
+		err = addTLS(source, ingress, rule.Host)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ingress, nil
+}
+
+var nameSanitizer = regexp.MustCompile(`[^a-z0-9]+`)
+
+// nameFromURL converts the host and path of an ingress to a string usable in resource names.
+func nameFromURL(ingress nais_io_v1.Ingress) string {
+	name := strings.TrimPrefix(string(ingress), "https://")
+	return strings.Trim(nameSanitizer.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
 func linkerdIngresses(source resource.Source, ast *resource.Ast, options resource.Options, naisIngresses []nais_io_v1.Ingress, livenessPath, serviceProtocol string, naisAnnotations map[string]string) error {
 	ingresses, err := nginxIngresses(source, options, naisIngresses, livenessPath, serviceProtocol, naisAnnotations)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if ingresses != nil {
 		for _, ing := range ingresses {
-			ast.AppendOperation(resource.OperationCreateOrUpdate, ing)
+			ast.AppendOperation(resource.OperationCreateOrUpdate, ingressObject(options, ing))
+			for _, cert := range certificates(source, options, ing) {
+				ast.AppendOperation(resource.OperationCreateOrUpdate, cert)
+			}
 		}
 	}
 	return nil
 }
 
-func onPremIngresses(source resource.Source, ast *resource.Ast, naisIngresses []nais_io_v1.Ingress, livenessPath string) error {
+func onPremIngresses(source resource.Source, ast *resource.Ast, options resource.Options, naisIngresses []nais_io_v1.Ingress, livenessPath string) error {
 	rules, err := ingressRules(source, naisIngresses)
 	if err != nil {
 		return err
@@ -201,18 +289,40 @@ func onPremIngresses(source resource.Source, ast *resource.Ast, naisIngresses []
 	}
 
 	ingress := createIngressBase(source, rules, livenessPath)
-	ast.AppendOperation(resource.OperationCreateOrUpdate, ingress)
+	for _, rule := range rules {
+		if options.CustomDomain(rule.Host) {
+			err = addTLS(source, ingress, rule.Host)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	ast.AppendOperation(resource.OperationCreateOrUpdate, ingressObject(options, ingress))
+	for _, cert := range certificates(source, options, ingress) {
+		ast.AppendOperation(resource.OperationCreateOrUpdate, cert)
+	}
 	return nil
 }
 
 func Create(source resource.Source, ast *resource.Ast, options resource.Options, naisIngresses []nais_io_v1.Ingress, livenessPath, serviceProtocol string, naisAnnotations map[string]string) error {
-	if options.Linkerd {
+	_, hasTrafficControls := naisAnnotations[TrafficControlAnnotation]
+	if hasTrafficControls && (options.GatewayAPI || !options.Linkerd) {
+		return fmt.Errorf("annotation '%s' is only supported for nginx ingresses", TrafficControlAnnotation)
+	}
+
+	if options.GatewayAPI {
+		err := gatewayRoutes(source, ast, options, naisIngresses)
+		if err != nil {
+			return fmt.Errorf("create http routes: %s", err)
+		}
+	} else if options.Linkerd {
 		err := linkerdIngresses(source, ast, options, naisIngresses, livenessPath, serviceProtocol, naisAnnotations)
 		if err != nil {
 			return fmt.Errorf("create ingresses: %s", err)
 		}
 	} else {
-		err := onPremIngresses(source, ast, naisIngresses, livenessPath)
+		err := onPremIngresses(source, ast, options, naisIngresses, livenessPath)
 		if err != nil {
 			return fmt.Errorf("create ingresses: %s", err)
 		}
@@ -0,0 +1,64 @@
+package ingress
+
+import (
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	networkingv1 "k8s.io/api/networking/v1"
+	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const ingressClassAnnotation = "kubernetes.io/ingress.class"
+
+// ingressObject returns an Ingress in the API version served by the cluster.
+func ingressObject(options resource.Options, ingress *networkingv1.Ingress) client.Object {
+	if options.IngressV1beta1 {
+		return legacyIngress(ingress)
+	}
+	return ingress
+}
+
+// legacyIngress converts an Ingress to networking.k8s.io/v1beta1, for clusters that do not serve networking.k8s.io/v1.
+// The ingress class is set with an annotation, and paths have no path type, as these clusters may not support them.
+func legacyIngress(ingress *networkingv1.Ingress) *networkingv1beta1.Ingress {
+	legacy := &networkingv1beta1.Ingress{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Ingress",
+			APIVersion: "networking.k8s.io/v1beta1",
+		},
+		ObjectMeta: *ingress.ObjectMeta.DeepCopy(),
+	}
+
+	if ingress.Spec.IngressClassName != nil {
+		legacy.Annotations[ingressClassAnnotation] = *ingress.Spec.IngressClassName
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		legacyRule := networkingv1beta1.IngressRule{
+			Host: rule.Host,
+		}
+		if rule.HTTP != nil {
+			legacyRule.HTTP = &networkingv1beta1.HTTPIngressRuleValue{}
+			for _, path := range rule.HTTP.Paths {
+				legacyRule.HTTP.Paths = append(legacyRule.HTTP.Paths, networkingv1beta1.HTTPIngressPath{
+					Path: path.Path,
+					Backend: networkingv1beta1.IngressBackend{
+						ServiceName: path.Backend.Service.Name,
+						ServicePort: intstr.FromInt(int(path.Backend.Service.Port.Number)),
+					},
+				})
+			}
+		}
+		legacy.Spec.Rules = append(legacy.Spec.Rules, legacyRule)
+	}
+
+	for _, tls := range ingress.Spec.TLS {
+		legacy.Spec.TLS = append(legacy.Spec.TLS, networkingv1beta1.IngressTLS{
+			Hosts:      tls.Hosts,
+			SecretName: tls.SecretName,
+		})
+	}
+
+	return legacy
+}
@@ -0,0 +1,235 @@
+package ingress
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/util"
+)
+
+// Per-ingress traffic controls are specified as a YAML list in this annotation, e.g.
+//
+//	nais.io/ingress-traffic-control: |
+//	  - ingress: https://myapp.example.com/api
+//	    allowedSourceRanges:
+//	      - 10.0.0.0/8
+//	    rateLimit:
+//	      requestsPerSecond: 10
+//	    timeouts:
+//	      read: 60s
+//	    maxBodySize: 10m
+//	    redirectFrom:
+//	      - https://old-myapp.example.com
+const TrafficControlAnnotation = "nais.io/ingress-traffic-control"
+
+const (
+	nginxAnnotationPrefix = "nginx.ingress.kubernetes.io/"
+	nginxSnippetSuffix    = "-snippet"
+)
+
+const (
+	maxConnectTimeout = 75 * time.Second // nginx does not allow connect timeouts above 75 seconds
+	maxProxyTimeout   = time.Hour
+)
+
+var nginxSizeValidation = regexp.MustCompile(`^[0-9]+[kKmMgG]?$`)
+
+type RateLimit struct {
+	RequestsPerSecond int `json:"requestsPerSecond"`
+	BurstMultiplier   int `json:"burstMultiplier,omitempty"`
+}
+
+type Timeouts struct {
+	Connect string `json:"connect,omitempty"`
+	Read    string `json:"read,omitempty"`
+	Send    string `json:"send,omitempty"`
+}
+
+// TrafficControl contains settings for a single ingress of an application.
+type TrafficControl struct {
+	Ingress             nais_io_v1.Ingress   `json:"ingress"`
+	AllowedSourceRanges []string             `json:"allowedSourceRanges,omitempty"`
+	RateLimit           *RateLimit           `json:"rateLimit,omitempty"`
+	Timeouts            *Timeouts            `json:"timeouts,omitempty"`
+	MaxBodySize         string               `json:"maxBodySize,omitempty"`
+	RedirectFrom        []nais_io_v1.Ingress `json:"redirectFrom,omitempty"`
+}
+
+func validateTimeout(name, value string, max time.Duration) error {
+	if len(value) == 0 {
+		return nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return fmt.Errorf("%s timeout: %s", name, err)
+	}
+	if duration < time.Second || duration > max {
+		return fmt.Errorf("%s timeout must be between 1s and %s", name, max)
+	}
+	return nil
+}
+
+func (tc TrafficControl) Validate() error {
+	for _, cidr := range tc.AllowedSourceRanges {
+		_, _, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("allowed source range: %s", err)
+		}
+	}
+
+	if tc.RateLimit != nil {
+		if tc.RateLimit.RequestsPerSecond < 1 {
+			return fmt.Errorf("rate limit must allow at least one request per second")
+		}
+		if tc.RateLimit.BurstMultiplier < 0 {
+			return fmt.Errorf("rate limit burst multiplier must not be negative")
+		}
+	}
+
+	if tc.Timeouts != nil {
+		if err := validateTimeout("connect", tc.Timeouts.Connect, maxConnectTimeout); err != nil {
+			return err
+		}
+		if err := validateTimeout("read", tc.Timeouts.Read, maxProxyTimeout); err != nil {
+			return err
+		}
+		if err := validateTimeout("send", tc.Timeouts.Send, maxProxyTimeout); err != nil {
+			return err
+		}
+	}
+
+	if len(tc.MaxBodySize) > 0 && !nginxSizeValidation.MatchString(tc.MaxBodySize) {
+		return fmt.Errorf("max body size '%s' does not match regular expression '%s'", tc.MaxBodySize, nginxSizeValidation.String())
+	}
+
+	for _, redirect := range tc.RedirectFrom {
+		u, err := url.Parse(string(redirect))
+		if err != nil {
+			return fmt.Errorf("failed to parse redirect URL '%s': %s", redirect, err)
+		}
+		err = util.ValidateUrl(u)
+		if err != nil {
+			return err
+		}
+		if len(strings.Trim(u.Path, "/")) > 0 {
+			return fmt.Errorf("redirect URL '%s' must not contain a path", redirect)
+		}
+	}
+
+	return nil
+}
+
+// seconds converts a validated duration to the integer seconds used by nginx.
+func seconds(value string) string {
+	duration, _ := time.ParseDuration(value)
+	return strconv.Itoa(int(duration.Seconds()))
+}
+
+// NginxAnnotations renders traffic controls to ingress-nginx annotations.
+// See https://kubernetes.github.io/ingress-nginx/user-guide/nginx-configuration/annotations/
+func (tc TrafficControl) NginxAnnotations() map[string]string {
+	annotations := make(map[string]string)
+
+	if len(tc.AllowedSourceRanges) > 0 {
+		annotations[nginxAnnotationPrefix+"whitelist-source-range"] = strings.Join(tc.AllowedSourceRanges, ",")
+	}
+
+	if tc.RateLimit != nil {
+		annotations[nginxAnnotationPrefix+"limit-rps"] = strconv.Itoa(tc.RateLimit.RequestsPerSecond)
+		if tc.RateLimit.BurstMultiplier > 0 {
+			annotations[nginxAnnotationPrefix+"limit-burst-multiplier"] = strconv.Itoa(tc.RateLimit.BurstMultiplier)
+		}
+	}
+
+	if tc.Timeouts != nil {
+		if len(tc.Timeouts.Connect) > 0 {
+			annotations[nginxAnnotationPrefix+"proxy-connect-timeout"] = seconds(tc.Timeouts.Connect)
+		}
+		if len(tc.Timeouts.Read) > 0 {
+			annotations[nginxAnnotationPrefix+"proxy-read-timeout"] = seconds(tc.Timeouts.Read)
+		}
+		if len(tc.Timeouts.Send) > 0 {
+			annotations[nginxAnnotationPrefix+"proxy-send-timeout"] = seconds(tc.Timeouts.Send)
+		}
+	}
+
+	if len(tc.MaxBodySize) > 0 {
+		annotations[nginxAnnotationPrefix+"proxy-body-size"] = tc.MaxBodySize
+	}
+
+	return annotations
+}
+
+// TrafficControls parses and validates traffic controls from application annotations.
+// Every entry must refer to one of the application's ingresses.
+func TrafficControls(naisAnnotations map[string]string, naisIngresses []nais_io_v1.Ingress) (map[nais_io_v1.Ingress]TrafficControl, error) {
+	controls := make(map[nais_io_v1.Ingress]TrafficControl)
+
+	value, ok := naisAnnotations[TrafficControlAnnotation]
+	if !ok {
+		return controls, nil
+	}
+
+	parsed := make([]TrafficControl, 0)
+	err := yaml.Unmarshal([]byte(value), &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", TrafficControlAnnotation, err)
+	}
+
+	known := make(map[nais_io_v1.Ingress]bool)
+	for _, ingress := range naisIngresses {
+		known[ingress] = true
+	}
+
+	for _, tc := range parsed {
+		if !known[tc.Ingress] {
+			return nil, fmt.Errorf("traffic control for '%s' does not match any ingress", tc.Ingress)
+		}
+		if _, duplicate := controls[tc.Ingress]; duplicate {
+			return nil, fmt.Errorf("traffic control for '%s' is specified more than once", tc.Ingress)
+		}
+		err = tc.Validate()
+		if err != nil {
+			return nil, fmt.Errorf("traffic control for '%s': %s", tc.Ingress, err)
+		}
+		controls[tc.Ingress] = tc
+	}
+
+	return controls, nil
+}
+
+// validateNginxAnnotations rejects raw nginx annotations that are not explicitly allowed in this cluster.
+// Without an allowlist, all raw annotations except the snippet annotations are passed through to the ingress.
+// Snippets inject arbitrary configuration into nginx, and must always be allowed explicitly.
+// Typed traffic controls should be preferred, as raw annotations apply to every ingress of the application.
+func validateNginxAnnotations(naisAnnotations map[string]string, allowed []string) error {
+	allowedSet := make(map[string]bool)
+	for _, key := range allowed {
+		allowedSet[key] = true
+	}
+
+	keys := make([]string, 0, len(naisAnnotations))
+	for key := range naisAnnotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !strings.HasPrefix(key, nginxAnnotationPrefix) || allowedSet[key] {
+			continue
+		}
+		if len(allowed) > 0 || strings.HasSuffix(key, nginxSnippetSuffix) {
+			return fmt.Errorf("annotation '%s' is not allowed in this cluster; use the '%s' annotation to configure traffic controls", key, TrafficControlAnnotation)
+		}
+	}
+
+	return nil
+}
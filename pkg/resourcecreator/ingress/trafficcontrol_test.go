@@ -0,0 +1,56 @@
+package ingress_test
+
+import (
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/resourcecreator/ingress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrafficControls(t *testing.T) {
+	ingresses := []nais_io_v1.Ingress{"https://foo.bar", "https://foo.bar/api"}
+
+	parse := func(value string) (map[nais_io_v1.Ingress]ingress.TrafficControl, error) {
+		return ingress.TrafficControls(map[string]string{ingress.TrafficControlAnnotation: value}, ingresses)
+	}
+
+	t.Run("no annotation yields no traffic controls", func(t *testing.T) {
+		controls, err := ingress.TrafficControls(map[string]string{}, ingresses)
+		assert.NoError(t, err)
+		assert.Empty(t, controls)
+	})
+
+	t.Run("valid traffic controls are keyed by ingress", func(t *testing.T) {
+		controls, err := parse(`
+- ingress: https://foo.bar/api
+  timeouts:
+    send: 90s
+`)
+		assert.NoError(t, err)
+		assert.Len(t, controls, 1)
+		assert.Equal(t, "90", controls["https://foo.bar/api"].NginxAnnotations()["nginx.ingress.kubernetes.io/proxy-send-timeout"])
+	})
+
+	for _, test := range []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"unknown ingress", "- ingress: https://baz.bar", "does not match any ingress"},
+		{"duplicate ingress", "- ingress: https://foo.bar\n- ingress: https://foo.bar", "specified more than once"},
+		{"invalid cidr", "- ingress: https://foo.bar\n  allowedSourceRanges: [10.0.0.0]", "allowed source range"},
+		{"zero rate limit", "- ingress: https://foo.bar\n  rateLimit: {requestsPerSecond: 0}", "at least one request"},
+		{"connect timeout too long", "- ingress: https://foo.bar\n  timeouts: {connect: 2m}", "connect timeout must be between"},
+		{"invalid body size", "- ingress: https://foo.bar\n  maxBodySize: 10MB", "max body size"},
+		{"redirect with path", "- ingress: https://foo.bar\n  redirectFrom: [https://old.bar/path]", "must not contain a path"},
+		{"redirect without https", "- ingress: https://foo.bar\n  redirectFrom: [http://old.bar]", "does not start with 'https://'"},
+		{"malformed yaml", "ingress: https://foo.bar", "parse annotation"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := parse(test.value)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
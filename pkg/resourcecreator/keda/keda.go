@@ -0,0 +1,264 @@
+package keda
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/ghodss/yaml"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	keda_sh_v1alpha1 "github.com/nais/naiserator/pkg/apis/keda.sh/v1alpha1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Applications opt in to KEDA autoscaling with this annotation, which replaces the HorizontalPodAutoscaler
+// with a ScaledObject. Replicas are scaled between minReplicas, which may be zero, and replicas.max, e.g.
+//
+//	nais.io/keda: |
+//	  minReplicas: 0
+//	  cooldownPeriod: 300
+//	  triggers:
+//	    - type: kafka
+//	      topic: myteam.mytopic
+//	      consumerGroup: myapp
+//	      lagThreshold: 100
+//	    - type: prometheus
+//	      query: sum(rate(http_requests_total{app="myapp"}[2m]))
+//	      threshold: "100"
+//	    - type: cron
+//	      timezone: Europe/Oslo
+//	      start: 0 6 * * 1-5
+//	      end: 0 18 * * 1-5
+//	      desiredReplicas: 2
+const Annotation = "nais.io/keda"
+
+// Trigger types supported in the annotation.
+const (
+	TriggerKafka      = "kafka"
+	TriggerPrometheus = "prometheus"
+	TriggerCron       = "cron"
+)
+
+const defaultLagThreshold = 10
+
+// Kafka credentials are read by KEDA from the environment variables injected into the application container.
+var kafkaAuthentication = []keda_sh_v1alpha1.AuthEnvironment{
+	{Parameter: "ca", Name: "KAFKA_CA"},
+	{Parameter: "cert", Name: "KAFKA_CERTIFICATE"},
+	{Parameter: "key", Name: "KAFKA_PRIVATE_KEY"},
+}
+
+type Trigger struct {
+	Type string `json:"type"`
+
+	// Kafka consumer lag on a topic in the application's Aiven pool.
+	Topic         string `json:"topic,omitempty"`
+	ConsumerGroup string `json:"consumerGroup,omitempty"`
+	LagThreshold  int    `json:"lagThreshold,omitempty"`
+
+	// Prometheus query.
+	Query     string `json:"query,omitempty"`
+	Threshold string `json:"threshold,omitempty"`
+
+	// Cron window.
+	Timezone        string `json:"timezone,omitempty"`
+	Start           string `json:"start,omitempty"`
+	End             string `json:"end,omitempty"`
+	DesiredReplicas int    `json:"desiredReplicas,omitempty"`
+}
+
+type Autoscaling struct {
+	MinReplicas     *int      `json:"minReplicas,omitempty"`
+	PollingInterval *int      `json:"pollingInterval,omitempty"`
+	CooldownPeriod  *int      `json:"cooldownPeriod,omitempty"`
+	Triggers        []Trigger `json:"triggers"`
+}
+
+// Parse reads KEDA autoscaling settings from the annotation value.
+func Parse(annotation string) (*Autoscaling, error) {
+	autoscaling := &Autoscaling{}
+	err := yaml.Unmarshal([]byte(annotation), autoscaling)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+	return autoscaling, nil
+}
+
+func (t Trigger) validate(options resource.Options, naisKafka *nais_io_v1.Kafka, maxReplicas int) error {
+	switch t.Type {
+	case TriggerKafka:
+		if !options.KafkaratorEnabled || naisKafka == nil {
+			return fmt.Errorf("kafka triggers require kafka to be enabled for the application")
+		}
+		if len(t.Topic) == 0 || len(t.ConsumerGroup) == 0 {
+			return fmt.Errorf("kafka triggers require a topic and a consumer group")
+		}
+		if t.LagThreshold < 0 {
+			return fmt.Errorf("kafka lag threshold must be a positive number")
+		}
+	case TriggerPrometheus:
+		if len(options.Keda.PrometheusAddress) == 0 {
+			return fmt.Errorf("prometheus triggers are not available in this cluster")
+		}
+		if len(t.Query) == 0 || len(t.Threshold) == 0 {
+			return fmt.Errorf("prometheus triggers require a query and a threshold")
+		}
+		if _, err := strconv.ParseFloat(t.Threshold, 64); err != nil {
+			return fmt.Errorf("prometheus threshold '%s' is not a number", t.Threshold)
+		}
+	case TriggerCron:
+		if len(t.Timezone) == 0 || len(t.Start) == 0 || len(t.End) == 0 {
+			return fmt.Errorf("cron triggers require a timezone, a start and an end")
+		}
+		if t.DesiredReplicas < 1 || t.DesiredReplicas > maxReplicas {
+			return fmt.Errorf("cron trigger desired replicas must be between 1 and replicas.max (%d)", maxReplicas)
+		}
+	default:
+		return fmt.Errorf("unsupported trigger type '%s'; use one of '%s', '%s' or '%s'", t.Type, TriggerKafka, TriggerPrometheus, TriggerCron)
+	}
+	return nil
+}
+
+// Validate rejects settings that KEDA cannot act on, or that conflict with the application spec.
+func (a Autoscaling) Validate(options resource.Options, naisReplicas nais_io_v1.Replicas, naisKafka *nais_io_v1.Kafka) error {
+	if len(a.Triggers) == 0 {
+		return fmt.Errorf("at least one trigger is required")
+	}
+	if a.MinReplicas != nil && (*a.MinReplicas < 0 || *a.MinReplicas > naisReplicas.Max) {
+		return fmt.Errorf("minReplicas must be between 0 and replicas.max (%d)", naisReplicas.Max)
+	}
+	if a.PollingInterval != nil && *a.PollingInterval < 1 {
+		return fmt.Errorf("pollingInterval must be at least one second")
+	}
+	if a.CooldownPeriod != nil && *a.CooldownPeriod < 0 {
+		return fmt.Errorf("cooldownPeriod must be a positive number of seconds")
+	}
+	for i, trigger := range a.Triggers {
+		err := trigger.validate(options, naisKafka, naisReplicas.Max)
+		if err != nil {
+			return fmt.Errorf("trigger %d: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (t Trigger) scaleTrigger(source resource.Source, options resource.Options) keda_sh_v1alpha1.ScaleTriggers {
+	switch t.Type {
+	case TriggerKafka:
+		lagThreshold := t.LagThreshold
+		if lagThreshold == 0 {
+			lagThreshold = defaultLagThreshold
+		}
+		return keda_sh_v1alpha1.ScaleTriggers{
+			Type: TriggerKafka,
+			Metadata: map[string]string{
+				"bootstrapServersFromEnv": "KAFKA_BROKERS",
+				"consumerGroup":           t.ConsumerGroup,
+				"topic":                   t.Topic,
+				"lagThreshold":            strconv.Itoa(lagThreshold),
+				"tls":                     "enable",
+			},
+			AuthenticationRef: &keda_sh_v1alpha1.ScaledObjectAuthRef{
+				Name: source.GetName(),
+			},
+		}
+	case TriggerPrometheus:
+		return keda_sh_v1alpha1.ScaleTriggers{
+			Type: TriggerPrometheus,
+			Metadata: map[string]string{
+				"serverAddress": options.Keda.PrometheusAddress,
+				"query":         t.Query,
+				"threshold":     t.Threshold,
+			},
+		}
+	default:
+		return keda_sh_v1alpha1.ScaleTriggers{
+			Type: TriggerCron,
+			Metadata: map[string]string{
+				"timezone":        t.Timezone,
+				"start":           t.Start,
+				"end":             t.End,
+				"desiredReplicas": strconv.Itoa(t.DesiredReplicas),
+			},
+		}
+	}
+}
+
+func int32p(i *int) *int32 {
+	if i == nil {
+		return nil
+	}
+	return util.Int32p(int32(*i))
+}
+
+func triggerAuthentication(source resource.Source) *keda_sh_v1alpha1.TriggerAuthentication {
+	auth := &keda_sh_v1alpha1.TriggerAuthentication{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "TriggerAuthentication",
+			APIVersion: keda_sh_v1alpha1.GroupVersion.Identifier(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+	}
+	for _, env := range kafkaAuthentication {
+		env.ContainerName = source.GetName()
+		auth.Spec.Env = append(auth.Spec.Env, env)
+	}
+	return auth
+}
+
+// Create renders a KEDA ScaledObject in place of the HorizontalPodAutoscaler.
+// Switching between the two is handled by the synchronizer, which deletes the unreferenced autoscaler
+// before the new one is created.
+func Create(source resource.Source, ast *resource.Ast, options resource.Options, naisReplicas nais_io_v1.Replicas, naisKafka *nais_io_v1.Kafka, annotation string) error {
+	if !options.KedaEnabled {
+		return fmt.Errorf("annotation '%s' is not supported in this cluster", Annotation)
+	}
+
+	autoscaling, err := Parse(annotation)
+	if err != nil {
+		return err
+	}
+
+	err = autoscaling.Validate(options, naisReplicas, naisKafka)
+	if err != nil {
+		return fmt.Errorf("keda autoscaling: %s", err)
+	}
+
+	minReplicas := naisReplicas.Min
+	if autoscaling.MinReplicas != nil {
+		minReplicas = *autoscaling.MinReplicas
+	}
+
+	scaledObject := &keda_sh_v1alpha1.ScaledObject{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "ScaledObject",
+			APIVersion: keda_sh_v1alpha1.GroupVersion.Identifier(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+		Spec: keda_sh_v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &keda_sh_v1alpha1.ScaleTarget{
+				APIVersion: "apps/v1",
+				Kind:       options.WorkloadKind(),
+				Name:       source.GetName(),
+			},
+			PollingInterval: int32p(autoscaling.PollingInterval),
+			CooldownPeriod:  int32p(autoscaling.CooldownPeriod),
+			MinReplicaCount: util.Int32p(int32(minReplicas)),
+			MaxReplicaCount: util.Int32p(int32(naisReplicas.Max)),
+		},
+	}
+
+	needsKafkaAuthentication := false
+	for _, trigger := range autoscaling.Triggers {
+		scaledObject.Spec.Triggers = append(scaledObject.Spec.Triggers, trigger.scaleTrigger(source, options))
+		needsKafkaAuthentication = needsKafkaAuthentication || trigger.Type == TriggerKafka
+	}
+
+	if needsKafkaAuthentication {
+		ast.AppendOperation(resource.OperationCreateOrUpdate, triggerAuthentication(source))
+	}
+	ast.AppendOperation(resource.OperationCreateOrUpdate, scaledObject)
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package keda_test
+
+import (
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/keda"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	options := resource.Options{
+		KafkaratorEnabled: true,
+		KedaEnabled:       true,
+		Keda:              config.Keda{PrometheusAddress: "http://prometheus:9090"},
+	}
+	replicas := nais_io_v1.Replicas{Min: 2, Max: 4}
+	kafka := &nais_io_v1.Kafka{Pool: "pool"}
+
+	t.Run("valid settings", func(t *testing.T) {
+		autoscaling, err := keda.Parse(`
+minReplicas: 0
+triggers:
+  - type: kafka
+    topic: topic
+    consumerGroup: group
+`)
+		assert.NoError(t, err)
+		assert.NoError(t, autoscaling.Validate(options, replicas, kafka))
+	})
+
+	for _, test := range []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"no triggers", "minReplicas: 1", "at least one trigger is required"},
+		{"min above max", "{minReplicas: 5, triggers: [{type: cron, timezone: UTC, start: '0 6 * * *', end: '0 7 * * *', desiredReplicas: 1}]}", "minReplicas must be between 0 and replicas.max (4)"},
+		{"negative min", "{minReplicas: -1, triggers: [{type: cron, timezone: UTC, start: '0 6 * * *', end: '0 7 * * *', desiredReplicas: 1}]}", "minReplicas must be between 0 and replicas.max (4)"},
+		{"cron replicas above max", "{triggers: [{type: cron, timezone: UTC, start: '0 6 * * *', end: '0 7 * * *', desiredReplicas: 5}]}", "trigger 1: cron trigger desired replicas must be between 1 and replicas.max (4)"},
+		{"incomplete cron trigger", "{triggers: [{type: cron, start: '0 6 * * *'}]}", "trigger 1: cron triggers require a timezone, a start and an end"},
+		{"kafka trigger without topic", "{triggers: [{type: kafka, consumerGroup: group}]}", "trigger 1: kafka triggers require a topic and a consumer group"},
+		{"prometheus threshold is not a number", "{triggers: [{type: prometheus, query: up, threshold: many}]}", "trigger 1: prometheus threshold 'many' is not a number"},
+		{"unknown trigger type", "{triggers: [{type: rabbitmq}]}", "trigger 1: unsupported trigger type 'rabbitmq'; use one of 'kafka', 'prometheus' or 'cron'"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			autoscaling, err := keda.Parse(test.value)
+			assert.NoError(t, err)
+			assert.EqualError(t, autoscaling.Validate(options, replicas, kafka), test.err)
+		})
+	}
+
+	t.Run("prometheus triggers need a prometheus address", func(t *testing.T) {
+		autoscaling, err := keda.Parse("{triggers: [{type: prometheus, query: up, threshold: '1'}]}")
+		assert.NoError(t, err)
+		assert.EqualError(t, autoscaling.Validate(resource.Options{KedaEnabled: true}, replicas, kafka), "trigger 1: prometheus triggers are not available in this cluster")
+	})
+}
+
+func TestCreate(t *testing.T) {
+	t.Run("annotation is rejected when keda is not enabled", func(t *testing.T) {
+		err := keda.Create(nil, resource.NewAst(), resource.Options{}, nais_io_v1.Replicas{}, nil, "")
+		assert.EqualError(t, err, "annotation 'nais.io/keda' is not supported in this cluster")
+	})
+}
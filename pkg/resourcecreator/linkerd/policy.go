@@ -0,0 +1,126 @@
+package linkerd
+
+import (
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	policy_linkerd_io_v1alpha1 "github.com/nais/naiserator/pkg/apis/policy.linkerd.io/v1alpha1"
+	policy_linkerd_io_v1beta1 "github.com/nais/naiserator/pkg/apis/policy.linkerd.io/v1beta1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const policyGroup = "policy.linkerd.io"
+
+// identityRefs converts inbound access policy rules to Linkerd mTLS identities.
+// Every application runs with a service account of the same name, so the proxy identity of a caller
+// is given by its application name and namespace. Rules for other clusters cannot be enforced by the mesh.
+func identityRefs(source resource.Source, options resource.Options, rules nais_io_v1.AccessPolicyInboundRules) []policy_linkerd_io_v1alpha1.TargetReference {
+	refs := make([]policy_linkerd_io_v1alpha1.TargetReference, 0)
+	seen := make(map[policy_linkerd_io_v1alpha1.TargetReference]bool)
+
+	add := func(ref policy_linkerd_io_v1alpha1.TargetReference) {
+		if !seen[ref] {
+			seen[ref] = true
+			refs = append(refs, ref)
+		}
+	}
+
+	for _, rule := range rules.GetRules() {
+		if !rule.MatchesCluster(options.ClusterName) {
+			continue
+		}
+		namespace := rule.Namespace
+		if len(namespace) == 0 {
+			namespace = source.GetNamespace()
+		}
+		if rule.Application == "*" {
+			add(policy_linkerd_io_v1alpha1.TargetReference{Kind: "Namespace", Name: namespace})
+			continue
+		}
+		add(policy_linkerd_io_v1alpha1.TargetReference{Kind: "ServiceAccount", Name: rule.Application, Namespace: namespace})
+	}
+
+	for _, namespace := range options.LinkerdPolicy.PlatformNamespaces {
+		add(policy_linkerd_io_v1alpha1.TargetReference{Kind: "Namespace", Name: namespace})
+	}
+
+	return refs
+}
+
+func proxyProtocol(serviceProtocol string) string {
+	if serviceProtocol == "grpc" {
+		return policy_linkerd_io_v1beta1.ProxyProtocolGRPC
+	}
+	return policy_linkerd_io_v1beta1.ProxyProtocolUnknown
+}
+
+// CreatePolicy enforces inbound access policies with Linkerd.
+// A Server is created for the application port, and only meshed clients authenticated with
+// the identities of applications listed in the inbound access policy are authorized to use it.
+//
+// Access policies grant access to applications as a whole, so authorization is not done per path.
+func CreatePolicy(source resource.Source, ast *resource.Ast, options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy, serviceProtocol string) {
+	if !options.Linkerd || !options.LinkerdPolicyEnabled {
+		return
+	}
+
+	var rules nais_io_v1.AccessPolicyInboundRules
+	if naisAccessPolicy.Inbound != nil {
+		rules = naisAccessPolicy.Inbound.Rules
+	}
+
+	server := &policy_linkerd_io_v1beta1.Server{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Server",
+			APIVersion: policy_linkerd_io_v1beta1.GroupVersion.String(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+		Spec: policy_linkerd_io_v1beta1.ServerSpec{
+			PodSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					"app": source.GetName(),
+				},
+			},
+			Port:          intstr.FromString(nais_io_v1alpha1.DefaultPortName),
+			ProxyProtocol: proxyProtocol(serviceProtocol),
+		},
+	}
+
+	authentication := &policy_linkerd_io_v1alpha1.MeshTLSAuthentication{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "MeshTLSAuthentication",
+			APIVersion: policy_linkerd_io_v1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+		Spec: policy_linkerd_io_v1alpha1.MeshTLSAuthenticationSpec{
+			IdentityRefs: identityRefs(source, options, rules),
+		},
+	}
+
+	authorization := &policy_linkerd_io_v1alpha1.AuthorizationPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "AuthorizationPolicy",
+			APIVersion: policy_linkerd_io_v1alpha1.GroupVersion.String(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+		Spec: policy_linkerd_io_v1alpha1.AuthorizationPolicySpec{
+			TargetRef: policy_linkerd_io_v1alpha1.TargetReference{
+				Group: policyGroup,
+				Kind:  "Server",
+				Name:  server.GetName(),
+			},
+			RequiredAuthenticationRefs: []policy_linkerd_io_v1alpha1.TargetReference{
+				{
+					Group: policyGroup,
+					Kind:  "MeshTLSAuthentication",
+					Name:  authentication.GetName(),
+				},
+			},
+		},
+	}
+
+	ast.AppendOperation(resource.OperationCreateOrUpdate, server)
+	ast.AppendOperation(resource.OperationCreateOrUpdate, authentication)
+	ast.AppendOperation(resource.OperationCreateOrUpdate, authorization)
+}
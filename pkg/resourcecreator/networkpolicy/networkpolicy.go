@@ -1,25 +1,28 @@
 package networkpolicy
 
 import (
+	"fmt"
 	"net/url"
 
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
-	"github.com/nais/naiserator/pkg/util"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
-	prometheusPodSelectorLabelValue        = "prometheus" // Label value denoting the Prometheus pod-selector
-	prometheusNamespace                    = "nais"       // Which namespace Prometheus is installed in
-	nginxNamespac                          = "nginx"      // Which namespace Nginx ingress controller runs in
-	networkPolicyDefaultEgressAllowIPBlock = "0.0.0.0/0"  // The default IP block CIDR for the default allow network policies per app
+	networkPolicyDefaultEgressAllowIPBlock = "0.0.0.0/0" // The default IP block CIDR for the default allow network policies per app
 )
 
-func Create(source resource.Source, ast *resource.Ast, options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy, naisIngresses []nais_io_v1.Ingress, naisLeaderElection bool) {
+func Create(source resource.Source, ast *resource.Ast, options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy, naisIngresses []nais_io_v1.Ingress, naisLeaderElection bool) error {
 	if !options.NetworkPolicy {
-		return
+		return nil
+	}
+
+	egress, err := egressPolicy(options, naisAccessPolicy, naisLeaderElection)
+	if err != nil {
+		return fmt.Errorf("create network policy: %s", err)
 	}
 
 	networkPolicy := &networkingv1.NetworkPolicy{
@@ -28,10 +31,24 @@ func Create(source resource.Source, ast *resource.Ast, options resource.Options,
 			APIVersion: "networking.k8s.io/v1",
 		},
 		ObjectMeta: resource.CreateObjectMeta(source),
-		Spec:       networkPolicySpec(source.GetName(), options, naisAccessPolicy, naisIngresses, naisLeaderElection),
+		Spec:       networkPolicySpec(source.GetName(), options, naisAccessPolicy, naisIngresses, egress),
 	}
 
 	ast.AppendOperation(resource.OperationCreateOrUpdate, networkPolicy)
+
+	if !options.StrictEgressEnabled {
+		return nil
+	}
+
+	hosts := ExternalHosts(options, naisAccessPolicy)
+	for k, v := range strictEgressAnnotations(options, hosts) {
+		networkPolicy.Annotations[k] = v
+	}
+	if options.StrictEgress.Provider == config.StrictEgressProviderCilium {
+		ast.AppendOperation(resource.OperationCreateOrUpdate, ciliumNetworkPolicy(source, hosts))
+	}
+
+	return nil
 }
 
 func labelSelector(label string, value string) *metav1.LabelSelector {
@@ -42,7 +59,7 @@ func labelSelector(label string, value string) *metav1.LabelSelector {
 	}
 }
 
-func networkPolicySpec(appName string, options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy, naisIngresses []nais_io_v1.Ingress, leaderElection bool) networkingv1.NetworkPolicySpec {
+func networkPolicySpec(appName string, options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy, naisIngresses []nais_io_v1.Ingress, egress []networkingv1.NetworkPolicyEgressRule) networkingv1.NetworkPolicySpec {
 	return networkingv1.NetworkPolicySpec{
 		PodSelector: *labelSelector("app", appName),
 		PolicyTypes: []networkingv1.PolicyType{
@@ -50,14 +67,7 @@ func networkPolicySpec(appName string, options resource.Options, naisAccessPolic
 			networkingv1.PolicyTypeEgress,
 		},
 		Ingress: ingressPolicy(options, naisAccessPolicy.Inbound, naisIngresses),
-		Egress:  egressPolicy(options, naisAccessPolicy.Outbound, leaderElection),
-	}
-}
-
-func networkPolicyPeer(podLabelName, podLabelValue, namespace string) networkingv1.NetworkPolicyPeer {
-	return networkingv1.NetworkPolicyPeer{
-		NamespaceSelector: labelSelector("name", namespace),
-		PodSelector:       labelSelector(podLabelName, podLabelValue),
+		Egress:  egress,
 	}
 }
 
@@ -102,44 +112,55 @@ func networkPolicyApplicationRules(rules nais_io_v1.AccessPolicyBaseRules, optio
 func ingressPolicy(options resource.Options, naisAccessPolicyInbound *nais_io_v1.AccessPolicyInbound, naisIngresses []nais_io_v1.Ingress) []networkingv1.NetworkPolicyIngressRule {
 	rules := make([]networkingv1.NetworkPolicyIngressRule, 0)
 
-	rules = append(rules, networkPolicyIngressRule(networkPolicyPeer("app", prometheusPodSelectorLabelValue, prometheusNamespace)))
-	rules = append(rules, networkPolicyIngressRule(networkingv1.NetworkPolicyPeer{
-		NamespaceSelector: labelSelector("linkerd.io/is-control-plane", "true"),
-	}))
-	rules = append(rules, networkPolicyIngressRule(networkingv1.NetworkPolicyPeer{
-		NamespaceSelector: labelSelector("linkerd.io/extension", "viz"),
-		PodSelector:       labelSelector("component", "tap"),
-	}))
-	rules = append(rules, networkPolicyIngressRule(networkingv1.NetworkPolicyPeer{
-		NamespaceSelector: labelSelector("linkerd.io/extension", "viz"),
-		PodSelector:       labelSelector("component", "prometheus"),
-	}))
+	for _, peer := range platformIngressPeers(options) {
+		rules = append(rules, networkPolicyIngressRule(platformPeer(peer)))
+	}
 
 	if len(naisAccessPolicyInbound.Rules) > 0 {
 		rules = append(rules, networkPolicyIngressRule(networkPolicyApplicationRules(naisAccessPolicyInbound.Rules, options)...))
 	}
 
 	if len(naisIngresses) > 0 {
-		for _, ingress := range naisIngresses {
-			ur, err := url.Parse(string(ingress))
+		for _, naisIngress := range naisIngresses {
+			ur, err := url.Parse(string(naisIngress))
 			if err != nil {
 				continue
 			}
-			gw := util.ResolveIngressClass(ur.Host, options.GatewayMappings)
+			if options.GatewayAPI {
+				gw, _ := options.ResolveGateway(ur.Host)
+				if gw == nil {
+					continue
+				}
+				rules = append(rules, networkPolicyIngressRule(gatewayPeer(*gw)))
+				continue
+			}
+			gw, _ := options.ResolveIngressClass(ur.Host)
 			if gw == nil {
 				continue
 			}
-			rules = append(rules, networkPolicyIngressRule(networkingv1.NetworkPolicyPeer{
-				PodSelector:       labelSelector("app.kubernetes.io/instance", *gw),
-				NamespaceSelector: labelSelector("name", nginxNamespac),
-			}))
+			rules = append(rules, networkPolicyIngressRule(ingressControllerPeer(options, *gw)))
 		}
 	}
 
 	return rules
 }
 
-func egressPolicy(options resource.Options, naisAccessPolicyOutbound *nais_io_v1.AccessPolicyOutbound, leaderElection bool) []networkingv1.NetworkPolicyEgressRule {
+// gatewayPeer selects the pods implementing a Gateway API Gateway.
+// Gateways without a namespace are assumed to run in the application namespace.
+func gatewayPeer(gateway config.Gateway) networkingv1.NetworkPolicyPeer {
+	peer := networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: gateway.PodLabels.Map(),
+		},
+	}
+	if len(gateway.Namespace) > 0 {
+		peer.NamespaceSelector = labelSelector("name", gateway.Namespace)
+	}
+	return peer
+}
+
+func egressPolicy(options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy, leaderElection bool) ([]networkingv1.NetworkPolicyEgressRule, error) {
+	naisAccessPolicyOutbound := naisAccessPolicy.Outbound
 	defaultRules := defaultAllowEgress(options)
 
 	if len(naisAccessPolicyOutbound.Rules) > 0 {
@@ -147,6 +168,14 @@ func egressPolicy(options resource.Options, naisAccessPolicyOutbound *nais_io_v1
 		defaultRules = append(defaultRules, appRules)
 	}
 
+	if options.StrictEgressEnabled {
+		externalRules, err := strictEgressRules(options, ExternalHosts(options, naisAccessPolicy))
+		if err != nil {
+			return nil, err
+		}
+		defaultRules = append(defaultRules, externalRules...)
+	}
+
 	if leaderElection && len(options.GoogleProjectId) > 0 {
 		apiServerAccessRule := networkPolicyEgressRule(networkingv1.NetworkPolicyPeer{
 			IPBlock: &networkingv1.IPBlock{
@@ -156,31 +185,25 @@ func egressPolicy(options resource.Options, naisAccessPolicyOutbound *nais_io_v1
 		defaultRules = append(defaultRules, apiServerAccessRule)
 	}
 
-	return defaultRules
+	return defaultRules, nil
 }
 
 func defaultAllowEgress(options resource.Options) []networkingv1.NetworkPolicyEgressRule {
-	peers := make([]networkingv1.NetworkPolicyPeer, 0, 4)
+	peers := make([]networkingv1.NetworkPolicyPeer, 0)
 
-	peers = append(peers, networkingv1.NetworkPolicyPeer{
-		NamespaceSelector: labelSelector("linkerd.io/is-control-plane", "true"),
-	})
+	for _, peer := range platformEgressPeers(options) {
+		peers = append(peers, platformPeer(peer))
+	}
 
-	peers = append(peers, networkingv1.NetworkPolicyPeer{
-		PodSelector: labelSelector("k8s-app", "kube-dns"),
-		NamespaceSelector: &metav1.LabelSelector{
-			MatchLabels: map[string]string{
-				// select in all namespaces since labels on kube-system is regularly deleted in GCP
+	// In strict egress mode, external hosts must be declared in the access policy.
+	if !options.StrictEgressEnabled {
+		peers = append(peers, networkingv1.NetworkPolicyPeer{
+			IPBlock: &networkingv1.IPBlock{
+				CIDR:   networkPolicyDefaultEgressAllowIPBlock,
+				Except: options.AccessPolicyNotAllowedCIDRs,
 			},
-		},
-	})
-
-	peers = append(peers, networkingv1.NetworkPolicyPeer{
-		IPBlock: &networkingv1.IPBlock{
-			CIDR:   networkPolicyDefaultEgressAllowIPBlock,
-			Except: options.AccessPolicyNotAllowedCIDRs,
-		},
-	})
+		})
+	}
 
 	return []networkingv1.NetworkPolicyEgressRule{
 		networkPolicyEgressRule(peers...),
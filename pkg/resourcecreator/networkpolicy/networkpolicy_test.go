@@ -7,6 +7,7 @@ import (
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
 	"github.com/nais/naiserator/pkg/test/fixtures"
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v2"
@@ -30,7 +31,8 @@ func TestNetworkPolicy(t *testing.T) {
 		err := app.ApplyDefaults()
 		assert.NoError(t, err)
 
-		networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		err = networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		assert.NoError(t, err)
 		networkPolicy := ast.Operations[0].Resource.(*networking.NetworkPolicy)
 
 		assert.Len(t, networkPolicy.Spec.Egress, 1)
@@ -110,7 +112,8 @@ func TestNetworkPolicy(t *testing.T) {
 		err := app.ApplyDefaults()
 		assert.NoError(t, err)
 
-		networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		err = networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		assert.NoError(t, err)
 		networkPolicy := ast.Operations[0].Resource.(*networking.NetworkPolicy)
 
 		matchLabels := map[string]string{
@@ -127,7 +130,8 @@ func TestNetworkPolicy(t *testing.T) {
 		err := app.ApplyDefaults()
 		assert.NoError(t, err)
 
-		networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		err = networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		assert.NoError(t, err)
 		networkPolicy := ast.Operations[0].Resource.(*networking.NetworkPolicy)
 
 		assert.Len(t, networkPolicy.Spec.Egress, 2)
@@ -141,7 +145,8 @@ func TestNetworkPolicy(t *testing.T) {
 		err := app.ApplyDefaults()
 		assert.NoError(t, err)
 
-		networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		err = networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		assert.NoError(t, err)
 		networkPolicy := ast.Operations[0].Resource.(*networking.NetworkPolicy)
 		assert.NotNil(t, networkPolicy)
 
@@ -150,4 +155,21 @@ func TestNetworkPolicy(t *testing.T) {
 		assert.Empty(t, networkPolicy.Spec.Ingress[1].From[0].PodSelector)
 	})
 
+	t.Run("strict egress rejects external hosts resolving to disallowed ranges", func(t *testing.T) {
+		app := fixtures.MinimalApplication()
+		ast := resource.NewAst()
+		app.Spec.AccessPolicy.Outbound.External = []nais_io_v1.AccessPolicyExternalRule{{Host: "internal.example.com"}}
+		err := app.ApplyDefaults()
+		assert.NoError(t, err)
+
+		options := resourceOptions
+		options.StrictEgressEnabled = true
+		options.StrictEgress.Provider = config.StrictEgressProviderIPBlock
+		options.ExternalHostAddresses = map[string][]string{
+			"internal.example.com": {"12.1.2.3"},
+		}
+
+		err = networkpolicy.Create(app, ast, options, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+		assert.EqualError(t, err, "create network policy: external host 'internal.example.com' resolves to '12.1.2.3', which is within the disallowed range '12.0.0.0/12'")
+	})
 }
@@ -0,0 +1,97 @@
+package networkpolicy
+
+import (
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Platform components in the standard NAIS layout, used unless the cluster configuration says otherwise.
+var (
+	defaultIngressPeers = []config.NetworkPolicyPeer{
+		{
+			Name:              "prometheus",
+			NamespaceSelector: config.Labels{{Key: "name", Value: "nais"}},
+			PodSelector:       config.Labels{{Key: "app", Value: "prometheus"}},
+		},
+		{
+			Name:              "linkerd-control-plane",
+			NamespaceSelector: config.Labels{{Key: "linkerd.io/is-control-plane", Value: "true"}},
+		},
+		{
+			Name:              "linkerd-viz-tap",
+			NamespaceSelector: config.Labels{{Key: "linkerd.io/extension", Value: "viz"}},
+			PodSelector:       config.Labels{{Key: "component", Value: "tap"}},
+		},
+		{
+			Name:              "linkerd-viz-prometheus",
+			NamespaceSelector: config.Labels{{Key: "linkerd.io/extension", Value: "viz"}},
+			PodSelector:       config.Labels{{Key: "component", Value: "prometheus"}},
+		},
+	}
+
+	defaultEgressPeers = []config.NetworkPolicyPeer{
+		{
+			Name:              "linkerd-control-plane",
+			NamespaceSelector: config.Labels{{Key: "linkerd.io/is-control-plane", Value: "true"}},
+		},
+		{
+			Name: "kube-dns",
+			// select in all namespaces since labels on kube-system is regularly deleted in GCP
+			NamespaceSelector: config.Labels{},
+			PodSelector:       config.Labels{{Key: "k8s-app", Value: "kube-dns"}},
+		},
+	}
+
+	defaultIngressController = config.IngressController{
+		NamespaceSelector: config.Labels{{Key: "name", Value: "nginx"}},
+		ClassLabel:        "app.kubernetes.io/instance",
+	}
+)
+
+func platformIngressPeers(options resource.Options) []config.NetworkPolicyPeer {
+	if options.NetworkPolicyPlatform.IngressPeers == nil {
+		return defaultIngressPeers
+	}
+	return options.NetworkPolicyPlatform.IngressPeers
+}
+
+func platformEgressPeers(options resource.Options) []config.NetworkPolicyPeer {
+	if options.NetworkPolicyPlatform.EgressPeers == nil {
+		return defaultEgressPeers
+	}
+	return options.NetworkPolicyPlatform.EgressPeers
+}
+
+func ingressController(options resource.Options) config.IngressController {
+	if options.NetworkPolicyPlatform.IngressController == nil {
+		return defaultIngressController
+	}
+	return *options.NetworkPolicyPlatform.IngressController
+}
+
+func optionalSelector(matchLabels config.Labels) *metav1.LabelSelector {
+	if matchLabels == nil {
+		return nil
+	}
+	return &metav1.LabelSelector{
+		MatchLabels: matchLabels.Map(),
+	}
+}
+
+func platformPeer(peer config.NetworkPolicyPeer) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		NamespaceSelector: optionalSelector(peer.NamespaceSelector),
+		PodSelector:       optionalSelector(peer.PodSelector),
+	}
+}
+
+// ingressControllerPeer selects the ingress controller pods serving an ingress class.
+func ingressControllerPeer(options resource.Options, ingressClass string) networkingv1.NetworkPolicyPeer {
+	controller := ingressController(options)
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector:       labelSelector(controller.ClassLabel, ingressClass),
+		NamespaceSelector: optionalSelector(controller.NamespaceSelector),
+	}
+}
@@ -0,0 +1,257 @@
+package networkpolicy
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	cilium_io_v2 "github.com/nais/naiserator/pkg/apis/cilium.io/v2"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Namespaces can override the cluster default for strict egress by setting this annotation to "enabled" or "disabled".
+// The annotation has no effect unless strict egress is enabled as a feature.
+const StrictEgressAnnotation = "nais.io/strict-egress"
+
+// Annotations on generated network policies, describing which external destinations are allowed.
+// Connections to any other destination are dropped by the CNI, so these are the first thing to check
+// when an application cannot reach an external host.
+const (
+	StrictEgressProviderAnnotation = "nais.io/strict-egress-provider"
+	StrictEgressHostsAnnotation    = "nais.io/strict-egress-hosts"
+)
+
+const defaultExternalPort = 443
+
+// ExternalHosts returns all external hosts an application may reach in strict egress mode;
+// the hosts declared in the access policy, and hosts allowed for all applications in this cluster.
+func ExternalHosts(options resource.Options, naisAccessPolicy nais_io_v1.AccessPolicy) []nais_io_v1.AccessPolicyExternalRule {
+	rules := make([]nais_io_v1.AccessPolicyExternalRule, 0)
+	seen := make(map[string]bool)
+
+	if naisAccessPolicy.Outbound != nil {
+		for _, rule := range naisAccessPolicy.Outbound.External {
+			if len(rule.Host) == 0 || seen[rule.Host] {
+				continue
+			}
+			seen[rule.Host] = true
+			rules = append(rules, rule)
+		}
+	}
+
+	for _, host := range options.StrictEgress.AllowedHosts {
+		if len(host) == 0 || seen[host] {
+			continue
+		}
+		seen[host] = true
+		rules = append(rules, nais_io_v1.AccessPolicyExternalRule{Host: host})
+	}
+
+	return rules
+}
+
+func IsWildcardHost(host string) bool {
+	return strings.HasPrefix(host, "*.")
+}
+
+func IsAddress(host string) bool {
+	return net.ParseIP(host) != nil
+}
+
+func externalPorts(rule nais_io_v1.AccessPolicyExternalRule) []int {
+	if len(rule.Ports) == 0 {
+		return []int{defaultExternalPort}
+	}
+	ports := make([]int, 0, len(rule.Ports))
+	for _, port := range rule.Ports {
+		ports = append(ports, int(port.Port))
+	}
+	return ports
+}
+
+func networkPolicyPorts(rule nais_io_v1.AccessPolicyExternalRule) []networkingv1.NetworkPolicyPort {
+	protocol := corev1.ProtocolTCP
+	ports := make([]networkingv1.NetworkPolicyPort, 0)
+	for _, port := range externalPorts(rule) {
+		p := intstr.FromInt(port)
+		ports = append(ports, networkingv1.NetworkPolicyPort{
+			Protocol: &protocol,
+			Port:     &p,
+		})
+	}
+	return ports
+}
+
+func addressCIDR(address string) string {
+	if net.ParseIP(address).To4() != nil {
+		return address + "/32"
+	}
+	return address + "/128"
+}
+
+func notAllowed(address string, notAllowedCIDRs []string) (string, bool) {
+	ip := net.ParseIP(address)
+	for _, cidr := range notAllowedCIDRs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		if network.Contains(ip) {
+			return cidr, true
+		}
+	}
+	return "", false
+}
+
+// addresses returns the addresses an external host is allowed to connect to with the given provider.
+// With Cilium, only literal IP addresses need IP blocks; host names are handled by FQDN policies.
+// Host names that could not be resolved are not allowed any addresses.
+func addresses(options resource.Options, host string) ([]string, error) {
+	if IsAddress(host) {
+		return []string{host}, nil
+	}
+	if options.StrictEgress.Provider == config.StrictEgressProviderCilium {
+		return nil, nil
+	}
+	if IsWildcardHost(host) {
+		return nil, fmt.Errorf("wildcard host '%s' requires the '%s' strict egress provider", host, config.StrictEgressProviderCilium)
+	}
+	return options.ExternalHostAddresses[host], nil
+}
+
+// strictEgressRules allows traffic to the resolved addresses of external hosts.
+func strictEgressRules(options resource.Options, hosts []nais_io_v1.AccessPolicyExternalRule) ([]networkingv1.NetworkPolicyEgressRule, error) {
+	rules := make([]networkingv1.NetworkPolicyEgressRule, 0)
+
+	for _, host := range hosts {
+		addrs, err := addresses(options, host.Host)
+		if err != nil {
+			return nil, err
+		}
+		if len(addrs) == 0 {
+			continue
+		}
+
+		peers := make([]networkingv1.NetworkPolicyPeer, 0, len(addrs))
+		for _, address := range addrs {
+			if cidr, denied := notAllowed(address, options.AccessPolicyNotAllowedCIDRs); denied {
+				return nil, fmt.Errorf("external host '%s' resolves to '%s', which is within the disallowed range '%s'", host.Host, address, cidr)
+			}
+			peers = append(peers, networkingv1.NetworkPolicyPeer{
+				IPBlock: &networkingv1.IPBlock{
+					CIDR: addressCIDR(address),
+				},
+			})
+		}
+
+		rules = append(rules, networkingv1.NetworkPolicyEgressRule{
+			To:    peers,
+			Ports: networkPolicyPorts(host),
+		})
+	}
+
+	return rules, nil
+}
+
+// DescribeExternalHosts lists the allowed external destinations, including resolved addresses.
+func DescribeExternalHosts(options resource.Options, hosts []nais_io_v1.AccessPolicyExternalRule) []string {
+	descriptions := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		resolved := options.ExternalHostAddresses[host.Host]
+		if IsAddress(host.Host) || options.StrictEgress.Provider == config.StrictEgressProviderCilium || len(resolved) == 0 {
+			descriptions = append(descriptions, host.Host)
+			continue
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%s=%s", host.Host, strings.Join(resolved, ",")))
+	}
+	sort.Strings(descriptions)
+	return descriptions
+}
+
+// ParseExternalHosts reads the resolved addresses of external hosts from a description made by DescribeExternalHosts.
+// Hosts described without addresses are left out.
+func ParseExternalHosts(description string) map[string][]string {
+	resolved := make(map[string][]string)
+	for _, entry := range strings.Split(description, ";") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || len(parts[1]) == 0 {
+			continue
+		}
+		resolved[parts[0]] = strings.Split(parts[1], ",")
+	}
+	return resolved
+}
+
+// strictEgressAnnotations describe the allowed external destinations on the network policy.
+func strictEgressAnnotations(options resource.Options, hosts []nais_io_v1.AccessPolicyExternalRule) map[string]string {
+	return map[string]string{
+		StrictEgressProviderAnnotation: options.StrictEgress.Provider,
+		StrictEgressHostsAnnotation:    strings.Join(DescribeExternalHosts(options, hosts), ";"),
+	}
+}
+
+// ciliumNetworkPolicy allows traffic to external hosts by name, letting Cilium track their addresses through DNS.
+// Connections dropped by policy can be inspected with `hubble observe --verdict DROPPED`.
+func ciliumNetworkPolicy(source resource.Source, hosts []nais_io_v1.AccessPolicyExternalRule) *cilium_io_v2.CiliumNetworkPolicy {
+	dnsRule := cilium_io_v2.EgressRule{
+		ToEndpoints: []metav1.LabelSelector{
+			{
+				MatchLabels: map[string]string{
+					"k8s:io.kubernetes.pod.namespace": "kube-system",
+					"k8s:k8s-app":                     "kube-dns",
+				},
+			},
+		},
+		ToPorts: []cilium_io_v2.PortRule{
+			{
+				Ports: []cilium_io_v2.PortProtocol{{Port: "53", Protocol: "ANY"}},
+				Rules: &cilium_io_v2.L7Rules{
+					DNS: []cilium_io_v2.FQDNSelector{{MatchPattern: "*"}},
+				},
+			},
+		},
+	}
+
+	egress := []cilium_io_v2.EgressRule{dnsRule}
+
+	for _, host := range hosts {
+		if IsAddress(host.Host) {
+			continue
+		}
+
+		selector := cilium_io_v2.FQDNSelector{MatchName: host.Host}
+		if IsWildcardHost(host.Host) {
+			selector = cilium_io_v2.FQDNSelector{MatchPattern: host.Host}
+		}
+
+		ports := make([]cilium_io_v2.PortProtocol, 0)
+		for _, port := range externalPorts(host) {
+			ports = append(ports, cilium_io_v2.PortProtocol{Port: strconv.Itoa(port), Protocol: "TCP"})
+		}
+
+		egress = append(egress, cilium_io_v2.EgressRule{
+			ToFQDNs: []cilium_io_v2.FQDNSelector{selector},
+			ToPorts: []cilium_io_v2.PortRule{{Ports: ports}},
+		})
+	}
+
+	return &cilium_io_v2.CiliumNetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "CiliumNetworkPolicy",
+			APIVersion: cilium_io_v2.GroupVersion.String(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+		Spec: &cilium_io_v2.Rule{
+			EndpointSelector: *labelSelector("app", source.GetName()),
+			Egress:           egress,
+		},
+	}
+}
@@ -57,6 +57,11 @@ func CreateSpec(ast *resource.Ast, resourceOptions resource.Options, appName str
 		podSpec.HostAliases = hostAliases(resourceOptions)
 	}
 
+	err = validateUserVolumeMounts(podSpec, ast.UserVolumeMounts)
+	if err != nil {
+		return nil, err
+	}
+
 	return podSpec, err
 }
 
@@ -122,6 +127,10 @@ func CreateAppContainer(app *nais_io_v1alpha1.Application, ast *resource.Ast, op
 	ast.Env = append(ast.Env, defaultEnvVars(app, options.ClusterName, app.Spec.Image)...)
 	filesFrom(ast, options.NativeSecrets, app.Spec.FilesFrom)
 	envFrom(ast, options.NativeSecrets, app.Spec.EnvFrom)
+	err := userVolumes(ast, app.Annotations)
+	if err != nil {
+		return err
+	}
 	lifecycle, err := lifecycle(app.Spec.PreStopHookPath, app.Spec.PreStopHook)
 	if err != nil {
 		return err
@@ -142,16 +151,24 @@ func CreateAppContainer(app *nais_io_v1alpha1.Application, ast *resource.Ast, op
 		VolumeMounts:    ast.VolumeMounts,
 	}
 
-	if app.Spec.Liveness != nil && len(app.Spec.Liveness.Path) > 0 {
-		container.LivenessProbe = probe(app.Spec.Port, *app.Spec.Liveness)
+	actions, err := probeActions(app.Annotations)
+	if err != nil {
+		return err
+	}
+
+	container.LivenessProbe, err = appProbe("liveness", app.Spec.Port, app.Spec.Liveness, actions.Liveness)
+	if err != nil {
+		return err
 	}
 
-	if app.Spec.Readiness != nil && len(app.Spec.Readiness.Path) > 0 {
-		container.ReadinessProbe = probe(app.Spec.Port, *app.Spec.Readiness)
+	container.ReadinessProbe, err = appProbe("readiness", app.Spec.Port, app.Spec.Readiness, actions.Readiness)
+	if err != nil {
+		return err
 	}
 
-	if app.Spec.Startup != nil && len(app.Spec.Startup.Path) > 0 {
-		container.StartupProbe = probe(app.Spec.Port, *app.Spec.Startup)
+	container.StartupProbe, err = appProbe("startup", app.Spec.Port, app.Spec.Startup, actions.Startup)
+	if err != nil {
+		return err
 	}
 
 	ast.Containers = append(ast.Containers, container)
@@ -164,6 +181,10 @@ func CreateNaisjobContainer(naisjob *nais_io_v1.Naisjob, ast *resource.Ast, opti
 	ast.Env = append(ast.Env, defaultEnvVars(naisjob, options.ClusterName, naisjob.Spec.Image)...)
 	filesFrom(ast, options.NativeSecrets, naisjob.Spec.FilesFrom)
 	envFrom(ast, options.NativeSecrets, naisjob.Spec.EnvFrom)
+	err := userVolumes(ast, naisjob.GetAnnotations())
+	if err != nil {
+		return err
+	}
 	lifecycle, err := lifecycle("", naisjob.Spec.PreStopHook)
 	if err != nil {
 		return err
@@ -204,7 +225,8 @@ func mapMerge(dst, src map[string]string) {
 
 func CreateAppObjectMeta(app *nais_io_v1alpha1.Application, ast *resource.Ast) metav1.ObjectMeta {
 	objectMeta := resource.CreateObjectMeta(app)
-	objectMeta.Annotations = ast.Annotations
+	objectMeta.Annotations = map[string]string{}
+	mapMerge(objectMeta.Annotations, ast.Annotations)
 	mapMerge(objectMeta.Labels, ast.Labels)
 
 	port := app.Spec.Prometheus.Port
@@ -212,7 +234,6 @@ func CreateAppObjectMeta(app *nais_io_v1alpha1.Application, ast *resource.Ast) m
 		port = strconv.Itoa(app.Spec.Port)
 	}
 
-	objectMeta.Annotations = map[string]string{}
 	if app.Spec.Prometheus.Enabled {
 		objectMeta.Annotations["prometheus.io/scrape"] = "true"
 		objectMeta.Annotations["prometheus.io/port"] = port
@@ -232,10 +253,9 @@ func CreateAppObjectMeta(app *nais_io_v1alpha1.Application, ast *resource.Ast) m
 
 func CreateNaisjobObjectMeta(naisjob *nais_io_v1.Naisjob, ast *resource.Ast) metav1.ObjectMeta {
 	objectMeta := resource.CreateObjectMeta(naisjob)
-	objectMeta.Annotations = ast.Annotations
-	mapMerge(objectMeta.Labels, ast.Labels)
-
 	objectMeta.Annotations = map[string]string{}
+	mapMerge(objectMeta.Annotations, ast.Annotations)
+	mapMerge(objectMeta.Labels, ast.Labels)
 
 	if len(naisjob.Spec.Logformat) > 0 {
 		objectMeta.Annotations["nais.io/logformat"] = naisjob.Spec.Logformat
@@ -0,0 +1,129 @@
+package pod
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Applications choose a TCP or exec check instead of an HTTP request for each probe with this annotation, e.g.
+//
+//	nais.io/probes: |
+//	  liveness:
+//	    tcp: {}
+//	  readiness:
+//	    tcp: {}
+//	  startup:
+//	    exec:
+//	      command: ["/app/healthcheck"]
+//
+// Port and timing are still taken from spec.liveness, spec.readiness and spec.startup, and the port defaults to the
+// application port.
+//
+// gRPC probes are rejected; the Kubernetes API in use has no native gRPC probe action.
+const ProbesAnnotation = "nais.io/probes"
+
+// GRPCProbe is only recognized to reject it with a helpful error.
+type GRPCProbe struct {
+	Service string `json:"service,omitempty"`
+}
+
+type TCPProbe struct{}
+
+type ExecProbe struct {
+	Command []string `json:"command"`
+}
+
+// ProbeAction is a check other than an HTTP request. Exactly one of the fields must be set.
+type ProbeAction struct {
+	GRPC *GRPCProbe `json:"grpc,omitempty"`
+	TCP  *TCPProbe  `json:"tcp,omitempty"`
+	Exec *ExecProbe `json:"exec,omitempty"`
+}
+
+type ProbeActions struct {
+	Liveness  *ProbeAction `json:"liveness,omitempty"`
+	Readiness *ProbeAction `json:"readiness,omitempty"`
+	Startup   *ProbeAction `json:"startup,omitempty"`
+}
+
+func probeActions(annotations map[string]string) (*ProbeActions, error) {
+	actions := &ProbeActions{}
+	value, ok := annotations[ProbesAnnotation]
+	if !ok {
+		return actions, nil
+	}
+
+	err := yaml.Unmarshal([]byte(value), actions)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", ProbesAnnotation, err)
+	}
+	return actions, nil
+}
+
+func (a *ProbeAction) validate() error {
+	if a.GRPC != nil {
+		return fmt.Errorf("grpc probes are not supported in this cluster; use tcp, or exec with a health check command from the image")
+	}
+	set := 0
+	for _, ok := range []bool{a.TCP != nil, a.Exec != nil} {
+		if ok {
+			set++
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of tcp or exec must be set")
+	}
+	if a.Exec != nil && len(a.Exec.Command) == 0 {
+		return fmt.Errorf("exec requires a command")
+	}
+	return nil
+}
+
+// appProbe creates a probe for the application container. Without an action from the annotation,
+// an HTTP probe is created if the probe has a path.
+func appProbe(name string, appPort int, naisProbe *nais_io_v1.Probe, action *ProbeAction) (*corev1.Probe, error) {
+	if action == nil {
+		if naisProbe != nil && len(naisProbe.Path) > 0 {
+			return probe(appPort, *naisProbe), nil
+		}
+		return nil, nil
+	}
+
+	err := action.validate()
+	if err != nil {
+		return nil, fmt.Errorf("annotation '%s': %s probe: %s", ProbesAnnotation, name, err)
+	}
+
+	timing := nais_io_v1.Probe{}
+	if naisProbe != nil {
+		timing = *naisProbe
+	}
+	if len(timing.Path) > 0 {
+		return nil, fmt.Errorf("annotation '%s': %s probe: spec.%s.path cannot be combined with a tcp or exec probe", ProbesAnnotation, name, name)
+	}
+
+	port := timing.Port
+	if port == 0 {
+		port = appPort
+	}
+
+	k8sprobe := &corev1.Probe{
+		InitialDelaySeconds: int32(timing.InitialDelay),
+		PeriodSeconds:       int32(timing.PeriodSeconds),
+		FailureThreshold:    int32(timing.FailureThreshold),
+		TimeoutSeconds:      int32(timing.Timeout),
+	}
+
+	switch {
+	case action.TCP != nil:
+		k8sprobe.Handler.TCPSocket = &corev1.TCPSocketAction{Port: intstr.FromInt(port)}
+	case action.Exec != nil:
+		k8sprobe.Handler.Exec = &corev1.ExecAction{Command: action.Exec.Command}
+	}
+
+	return k8sprobe, nil
+}
@@ -0,0 +1,225 @@
+package pod
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Applications relax single settings of the cluster security baseline for their own container with this annotation,
+// where the cluster allows it, e.g.
+//
+//	nais.io/security-context: |
+//	  runAsNonRoot: false
+//	  allowPrivilegeEscalation: true
+//	  readOnlyRootFilesystem: false
+//	  seccompProfile: unconfined
+//	  addCapabilities: [NET_BIND_SERVICE]
+//
+// Sidecars always follow the baseline.
+const SecurityContextAnnotation = "nais.io/security-context"
+
+// RelaxedSecurityContextAnnotation lists the settings an application has relaxed, so that they can be audited
+// on the pods.
+const RelaxedSecurityContextAnnotation = "nais.io/security-context-relaxed"
+
+const writableTmpVolume = "writable-tmp"
+
+var capabilityPattern = regexp.MustCompile(`^[A-Z][A-Z_]*$`)
+
+type SecurityContext struct {
+	RunAsNonRoot             *bool    `json:"runAsNonRoot,omitempty"`
+	AllowPrivilegeEscalation *bool    `json:"allowPrivilegeEscalation,omitempty"`
+	ReadOnlyRootFilesystem   *bool    `json:"readOnlyRootFilesystem,omitempty"`
+	SeccompProfile           string   `json:"seccompProfile,omitempty"`
+	AddCapabilities          []string `json:"addCapabilities,omitempty"`
+}
+
+// securityContextOverride reads the application's overrides, and returns the settings of the baseline it relaxes.
+// Settings that are tightened need no permission.
+func securityContextOverride(baseline config.SecurityBaseline, annotations map[string]string) (*SecurityContext, []string, error) {
+	override := &SecurityContext{}
+	value, ok := annotations[SecurityContextAnnotation]
+	if !ok {
+		return override, nil, nil
+	}
+
+	err := yaml.Unmarshal([]byte(value), override)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse annotation '%s': %s", SecurityContextAnnotation, err)
+	}
+
+	if len(override.SeccompProfile) > 0 && !config.ValidSeccompProfile(override.SeccompProfile) {
+		return nil, nil, fmt.Errorf("annotation '%s': seccompProfile must be one of 'runtime/default', 'docker/default', 'unconfined' or 'localhost/<path>'", SecurityContextAnnotation)
+	}
+	for _, capability := range override.AddCapabilities {
+		if !capabilityPattern.MatchString(capability) {
+			return nil, nil, fmt.Errorf("annotation '%s': '%s' is not a capability name, such as NET_BIND_SERVICE", SecurityContextAnnotation, capability)
+		}
+	}
+
+	relaxed := make([]string, 0)
+	for _, setting := range []struct {
+		name    string
+		relaxes bool
+	}{
+		{config.RelaxRunAsNonRoot, baseline.RunAsNonRoot && override.RunAsNonRoot != nil && !*override.RunAsNonRoot},
+		{config.RelaxCapabilities, len(override.AddCapabilities) > 0},
+		{config.RelaxPrivilegeEscalation, baseline.NoPrivilegeEscalation && override.AllowPrivilegeEscalation != nil && *override.AllowPrivilegeEscalation},
+		{config.RelaxSeccompProfile, len(override.SeccompProfile) > 0 && override.SeccompProfile != baseline.SeccompProfile},
+		{config.RelaxReadOnlyRootFilesystem, baseline.ReadOnlyRootFilesystem && override.ReadOnlyRootFilesystem != nil && !*override.ReadOnlyRootFilesystem},
+	} {
+		if !setting.relaxes {
+			continue
+		}
+		if !contains(baseline.Relaxable, setting.name) {
+			return nil, nil, fmt.Errorf("annotation '%s': relaxing %s is not allowed in this cluster", SecurityContextAnnotation, setting.name)
+		}
+		relaxed = append(relaxed, setting.name)
+	}
+
+	return override, relaxed, nil
+}
+
+// containerSecurityContext returns the security context of a container according to the baseline.
+// Overrides are only applied to the application container.
+func containerSecurityContext(baseline config.SecurityBaseline, override *SecurityContext) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{}
+
+	if baseline.RunAsNonRoot {
+		securityContext.RunAsNonRoot = util.Boolp(true)
+	}
+	if baseline.NoPrivilegeEscalation {
+		securityContext.AllowPrivilegeEscalation = util.Boolp(false)
+	}
+	if baseline.ReadOnlyRootFilesystem {
+		securityContext.ReadOnlyRootFilesystem = util.Boolp(true)
+	}
+	if len(baseline.DropCapabilities) > 0 {
+		securityContext.Capabilities = &corev1.Capabilities{}
+		for _, capability := range baseline.DropCapabilities {
+			securityContext.Capabilities.Drop = append(securityContext.Capabilities.Drop, corev1.Capability(capability))
+		}
+	}
+
+	if override == nil {
+		return securityContext
+	}
+
+	if override.RunAsNonRoot != nil {
+		securityContext.RunAsNonRoot = override.RunAsNonRoot
+	}
+	if override.AllowPrivilegeEscalation != nil {
+		securityContext.AllowPrivilegeEscalation = override.AllowPrivilegeEscalation
+	}
+	if override.ReadOnlyRootFilesystem != nil {
+		securityContext.ReadOnlyRootFilesystem = override.ReadOnlyRootFilesystem
+	}
+	if len(override.AddCapabilities) > 0 {
+		if securityContext.Capabilities == nil {
+			securityContext.Capabilities = &corev1.Capabilities{}
+		}
+		for _, capability := range override.AddCapabilities {
+			securityContext.Capabilities.Add = append(securityContext.Capabilities.Add, corev1.Capability(capability))
+		}
+	}
+
+	return securityContext
+}
+
+// writableTmp gives containers with a read-only root filesystem their own writable /tmp,
+// unless they already mount something there. It returns true if the volume is needed.
+func writableTmp(container *corev1.Container) bool {
+	if container.SecurityContext.ReadOnlyRootFilesystem == nil || !*container.SecurityContext.ReadOnlyRootFilesystem {
+		return false
+	}
+	for _, mount := range container.VolumeMounts {
+		if mount.MountPath == "/tmp" {
+			return false
+		}
+	}
+
+	// The mounts of the application container are shared with the Ast, so they must be copied before appending.
+	mounts := make([]corev1.VolumeMount, 0, len(container.VolumeMounts)+1)
+	mounts = append(mounts, container.VolumeMounts...)
+	container.VolumeMounts = append(mounts, corev1.VolumeMount{
+		Name:      writableTmpVolume,
+		MountPath: "/tmp",
+		SubPath:   container.Name,
+	})
+	return true
+}
+
+// platformSecurityContext returns the security context of a sidecar or init container added by the platform.
+// Platform images may run as root by default, so they run as the user set by the platform container itself,
+// or as the user configured for the cluster.
+func platformSecurityContext(baseline config.SecurityBaseline, existing *corev1.SecurityContext) *corev1.SecurityContext {
+	securityContext := containerSecurityContext(baseline, nil)
+	if existing != nil && existing.RunAsUser != nil {
+		securityContext.RunAsUser = existing.RunAsUser
+	} else if baseline.RunAsNonRoot && baseline.PlatformRunAsUser > 0 {
+		runAsUser := baseline.PlatformRunAsUser
+		securityContext.RunAsUser = &runAsUser
+	}
+	return securityContext
+}
+
+// SetSecurityContext applies the cluster security baseline to every container in the pod, including sidecars and
+// init containers. The seccomp profile is set with pod template annotations, which must be created afterwards.
+func SetSecurityContext(podSpec *corev1.PodSpec, ast *resource.Ast, resourceOptions resource.Options, appName string, annotations map[string]string) error {
+	if !resourceOptions.SecurityBaselineEnabled {
+		if _, ok := annotations[SecurityContextAnnotation]; ok {
+			return fmt.Errorf("annotation '%s' is not supported in this cluster", SecurityContextAnnotation)
+		}
+		return nil
+	}
+
+	baseline := resourceOptions.SecurityBaseline
+	override, relaxed, err := securityContextOverride(baseline, annotations)
+	if err != nil {
+		return err
+	}
+
+	needsTmp := false
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for i := range containers {
+			switch {
+			case containers[i].Name == appName:
+				containers[i].SecurityContext = containerSecurityContext(baseline, override)
+			case contains(platformContainerNames, containers[i].Name):
+				containers[i].SecurityContext = platformSecurityContext(baseline, containers[i].SecurityContext)
+			default:
+				containers[i].SecurityContext = containerSecurityContext(baseline, nil)
+			}
+			needsTmp = writableTmp(&containers[i]) || needsTmp
+		}
+	}
+
+	if needsTmp {
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: writableTmpVolume,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+	}
+
+	if len(baseline.SeccompProfile) > 0 {
+		ast.Annotations[corev1.SeccompPodAnnotationKey] = baseline.SeccompProfile
+	}
+	if len(override.SeccompProfile) > 0 && override.SeccompProfile != baseline.SeccompProfile {
+		ast.Annotations[corev1.SeccompContainerAnnotationKeyPrefix+appName] = override.SeccompProfile
+	}
+
+	if len(relaxed) > 0 {
+		ast.Annotations[RelaxedSecurityContextAnnotation] = strings.Join(relaxed, ",")
+	}
+
+	return nil
+}
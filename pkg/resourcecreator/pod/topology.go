@@ -0,0 +1,152 @@
+package pod
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Applications override how their replicas are spread across zones and nodes with this annotation, e.g.
+//
+//	nais.io/topology-spread: |
+//	  zone: DoNotSchedule
+//	  node: disabled
+//	  antiAffinity: required
+//
+// Settings that are left out follow the cluster policy. All spreading is disabled with
+//
+//	nais.io/topology-spread: disabled
+const TopologySpreadAnnotation = "nais.io/topology-spread"
+
+// TopologyDisabled turns off a spread constraint or the anti-affinity.
+const TopologyDisabled = "disabled"
+
+// Pod anti-affinity types.
+const (
+	AntiAffinityPreferred = "preferred"
+	AntiAffinityRequired  = "required"
+)
+
+const hostnameTopologyKey = "kubernetes.io/hostname"
+
+type TopologySpread struct {
+	Zone         string `json:"zone,omitempty"`
+	Node         string `json:"node,omitempty"`
+	AntiAffinity string `json:"antiAffinity,omitempty"`
+}
+
+// topologySpread merges the application's overrides with the cluster policy.
+func topologySpread(topology config.Topology, annotations map[string]string) (*TopologySpread, error) {
+	spread := &TopologySpread{
+		Zone:         topology.ZoneWhenUnsatisfiable,
+		Node:         topology.NodeWhenUnsatisfiable,
+		AntiAffinity: topology.AntiAffinity,
+	}
+
+	value, ok := annotations[TopologySpreadAnnotation]
+	if !ok {
+		return spread, nil
+	}
+	if value == TopologyDisabled {
+		return &TopologySpread{Zone: TopologyDisabled, Node: TopologyDisabled, AntiAffinity: TopologyDisabled}, nil
+	}
+
+	override := &TopologySpread{}
+	err := yaml.Unmarshal([]byte(value), override)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", TopologySpreadAnnotation, err)
+	}
+
+	for _, setting := range []struct {
+		name     string
+		value    string
+		target   *string
+		accepted []string
+	}{
+		{"zone", override.Zone, &spread.Zone, []string{string(corev1.DoNotSchedule), string(corev1.ScheduleAnyway), TopologyDisabled}},
+		{"node", override.Node, &spread.Node, []string{string(corev1.DoNotSchedule), string(corev1.ScheduleAnyway), TopologyDisabled}},
+		{"antiAffinity", override.AntiAffinity, &spread.AntiAffinity, []string{AntiAffinityPreferred, AntiAffinityRequired, TopologyDisabled}},
+	} {
+		if len(setting.value) == 0 {
+			continue
+		}
+		if !contains(setting.accepted, setting.value) {
+			return nil, fmt.Errorf("annotation '%s': %s must be one of %v", TopologySpreadAnnotation, setting.name, setting.accepted)
+		}
+		*setting.target = setting.value
+	}
+
+	return spread, nil
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// spreadConstraint returns nil when spreading across the topology is disabled.
+func spreadConstraint(topologyKey, whenUnsatisfiable string, maxSkew int, selector *metav1.LabelSelector) *corev1.TopologySpreadConstraint {
+	if len(topologyKey) == 0 || maxSkew < 1 || len(whenUnsatisfiable) == 0 || whenUnsatisfiable == TopologyDisabled {
+		return nil
+	}
+	return &corev1.TopologySpreadConstraint{
+		MaxSkew:           int32(maxSkew),
+		TopologyKey:       topologyKey,
+		WhenUnsatisfiable: corev1.UnsatisfiableConstraintAction(whenUnsatisfiable),
+		LabelSelector:     selector,
+	}
+}
+
+// SetTopologySpread spreads the replicas of an application across zones and nodes according to the cluster policy,
+// unless the application overrides it.
+func SetTopologySpread(podSpec *corev1.PodSpec, resourceOptions resource.Options, appName string, annotations map[string]string) error {
+	spread, err := topologySpread(resourceOptions.Topology, annotations)
+	if err != nil {
+		return err
+	}
+
+	selector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{"app": appName},
+	}
+
+	for _, constraint := range []*corev1.TopologySpreadConstraint{
+		spreadConstraint(resourceOptions.Topology.ZoneKey, spread.Zone, resourceOptions.Topology.ZoneMaxSkew, selector),
+		spreadConstraint(hostnameTopologyKey, spread.Node, resourceOptions.Topology.NodeMaxSkew, selector),
+	} {
+		if constraint != nil {
+			podSpec.TopologySpreadConstraints = append(podSpec.TopologySpreadConstraints, *constraint)
+		}
+	}
+
+	term := corev1.PodAffinityTerm{
+		LabelSelector: selector,
+		TopologyKey:   hostnameTopologyKey,
+	}
+
+	switch spread.AntiAffinity {
+	case AntiAffinityPreferred:
+		podSpec.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+					{Weight: 100, PodAffinityTerm: term},
+				},
+			},
+		}
+	case AntiAffinityRequired:
+		podSpec.Affinity = &corev1.Affinity{
+			PodAntiAffinity: &corev1.PodAntiAffinity{
+				RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{term},
+			},
+		}
+	}
+
+	return nil
+}
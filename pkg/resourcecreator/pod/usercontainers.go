@@ -0,0 +1,170 @@
+package pod
+
+import (
+	"fmt"
+
+	"github.com/ghodss/yaml"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Applications add their own init containers and sidecars to the pod with this annotation, e.g.
+//
+//	nais.io/containers: |
+//	  initContainers:
+//	    - name: migrate
+//	      image: ghcr.io/myteam/migrations:1.2.3
+//	      command: ["/migrate", "up"]
+//	      resources:
+//	        requests: {cpu: 50m, memory: 64Mi}
+//	        limits: {cpu: 200m, memory: 128Mi}
+//	  sidecars:
+//	    - name: cache
+//	      image: redis:6
+//	      resources:
+//	        requests: {cpu: 50m, memory: 64Mi}
+//	        limits: {cpu: 200m, memory: 256Mi}
+//
+// The containers get the same environment and volume mounts as the application container, and init containers run
+// after those of the platform.
+const UserContainersAnnotation = "nais.io/containers"
+
+// Names of containers added by the platform, which applications cannot use even if the feature is not in use.
+var platformContainerNames = []string{
+	"cloudsql-proxy",
+	"elector",
+	"linkerd-init",
+	"linkerd-proxy",
+	"secure-logs-configmap-reload",
+	"secure-logs-fluentd",
+	"vks-init",
+	"vks-sidecar",
+}
+
+type UserContainer struct {
+	Name      string                          `json:"name"`
+	Image     string                          `json:"image"`
+	Command   []string                        `json:"command,omitempty"`
+	Args      []string                        `json:"args,omitempty"`
+	Env       nais_io_v1.EnvVars              `json:"env,omitempty"`
+	Resources nais_io_v1.ResourceRequirements `json:"resources"`
+}
+
+type UserContainers struct {
+	InitContainers []UserContainer `json:"initContainers,omitempty"`
+	Sidecars       []UserContainer `json:"sidecars,omitempty"`
+}
+
+func (c UserContainer) validate() error {
+	if len(c.Image) == 0 {
+		return fmt.Errorf("image is required")
+	}
+
+	requests, limits := c.Resources.Requests, c.Resources.Limits
+	if requests == nil || limits == nil {
+		return fmt.Errorf("resources.requests and resources.limits are required")
+	}
+	for _, quantity := range []struct {
+		name  string
+		value string
+	}{
+		{"resources.requests.cpu", requests.Cpu},
+		{"resources.requests.memory", requests.Memory},
+		{"resources.limits.cpu", limits.Cpu},
+		{"resources.limits.memory", limits.Memory},
+	} {
+		_, err := k8sresource.ParseQuantity(quantity.value)
+		if err != nil {
+			return fmt.Errorf("%s '%s' is not a valid quantity", quantity.name, quantity.value)
+		}
+	}
+
+	return nil
+}
+
+// validateNames rejects invalid names, and names that are already used by the application, the platform or
+// another user container.
+func (u *UserContainers) validateNames(appName string, ast *resource.Ast) error {
+	used := map[string]bool{appName: true}
+	for _, name := range platformContainerNames {
+		used[name] = true
+	}
+	for _, containers := range [][]corev1.Container{ast.InitContainers, ast.Containers} {
+		for _, container := range containers {
+			used[container.Name] = true
+		}
+	}
+
+	for _, containers := range [][]UserContainer{u.InitContainers, u.Sidecars} {
+		for _, container := range containers {
+			if errs := validation.IsDNS1123Label(container.Name); len(errs) > 0 {
+				return fmt.Errorf("container name '%s' is invalid: %s", container.Name, errs[0])
+			}
+			if used[container.Name] {
+				return fmt.Errorf("container name '%s' is already in use", container.Name)
+			}
+			used[container.Name] = true
+		}
+	}
+
+	return nil
+}
+
+func (c UserContainer) container(ast *resource.Ast) corev1.Container {
+	env := make([]corev1.EnvVar, 0, len(ast.Env)+len(c.Env))
+	env = append(env, ast.Env...)
+	env = append(env, c.Env.ToKubernetes()...)
+
+	return corev1.Container{
+		Name:            c.Name,
+		Image:           c.Image,
+		Command:         c.Command,
+		Args:            c.Args,
+		Resources:       ResourceLimits(c.Resources),
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Env:             env,
+		EnvFrom:         ast.EnvFrom,
+		VolumeMounts:    ast.VolumeMounts,
+	}
+}
+
+// CreateUserContainers adds the init containers and sidecars declared by the application.
+// The application container must be created first, so that its environment and volume mounts are complete.
+func CreateUserContainers(appName string, ast *resource.Ast, annotations map[string]string) error {
+	value, ok := annotations[UserContainersAnnotation]
+	if !ok {
+		return nil
+	}
+
+	userContainers := &UserContainers{}
+	err := yaml.Unmarshal([]byte(value), userContainers)
+	if err != nil {
+		return fmt.Errorf("parse annotation '%s': %s", UserContainersAnnotation, err)
+	}
+
+	err = userContainers.validateNames(appName, ast)
+	if err != nil {
+		return fmt.Errorf("annotation '%s': %s", UserContainersAnnotation, err)
+	}
+
+	for _, containers := range [][]UserContainer{userContainers.InitContainers, userContainers.Sidecars} {
+		for _, container := range containers {
+			err = container.validate()
+			if err != nil {
+				return fmt.Errorf("annotation '%s': container '%s': %s", UserContainersAnnotation, container.Name, err)
+			}
+		}
+	}
+
+	for _, container := range userContainers.InitContainers {
+		ast.InitContainers = append(ast.InitContainers, container.container(ast))
+	}
+	for _, container := range userContainers.Sidecars {
+		ast.Containers = append(ast.Containers, container.container(ast))
+	}
+
+	return nil
+}
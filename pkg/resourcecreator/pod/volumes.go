@@ -0,0 +1,168 @@
+package pod
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Applications mount scratch space and existing persistent volume claims with this annotation, e.g.
+//
+//	nais.io/volumes: |
+//	  - name: scratch
+//	    mountPath: /scratch
+//	    emptyDir:
+//	      sizeLimit: 1Gi
+//	      medium: Memory
+//	  - name: shared
+//	    mountPath: /shared
+//	    readOnly: true
+//	    persistentVolumeClaim:
+//	      claimName: my-claim
+//
+// The volumes are mounted in the application container, and in containers added by the application.
+const VolumesAnnotation = "nais.io/volumes"
+
+type EmptyDirVolume struct {
+	SizeLimit string `json:"sizeLimit,omitempty"`
+	// Either empty for the node's default medium, or Memory.
+	Medium string `json:"medium,omitempty"`
+}
+
+type PersistentVolumeClaimVolume struct {
+	ClaimName string `json:"claimName"`
+}
+
+type UserVolume struct {
+	Name                  string                       `json:"name"`
+	MountPath             string                       `json:"mountPath"`
+	ReadOnly              bool                         `json:"readOnly,omitempty"`
+	EmptyDir              *EmptyDirVolume              `json:"emptyDir,omitempty"`
+	PersistentVolumeClaim *PersistentVolumeClaimVolume `json:"persistentVolumeClaim,omitempty"`
+	// Generic ephemeral volumes are not available in the Kubernetes API in use, and are rejected.
+	Ephemeral interface{} `json:"ephemeral,omitempty"`
+}
+
+func (v UserVolume) volumeSource() (corev1.VolumeSource, error) {
+	if v.Ephemeral != nil {
+		return corev1.VolumeSource{}, fmt.Errorf("generic ephemeral volumes are not supported in this cluster")
+	}
+	if (v.EmptyDir == nil) == (v.PersistentVolumeClaim == nil) {
+		return corev1.VolumeSource{}, fmt.Errorf("exactly one of emptyDir or persistentVolumeClaim must be set")
+	}
+
+	if v.PersistentVolumeClaim != nil {
+		if errs := validation.IsDNS1123Subdomain(v.PersistentVolumeClaim.ClaimName); len(errs) > 0 {
+			return corev1.VolumeSource{}, fmt.Errorf("claimName '%s' is invalid: %s", v.PersistentVolumeClaim.ClaimName, errs[0])
+		}
+		return corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: v.PersistentVolumeClaim.ClaimName,
+				ReadOnly:  v.ReadOnly,
+			},
+		}, nil
+	}
+
+	emptyDir := &corev1.EmptyDirVolumeSource{}
+	switch corev1.StorageMedium(v.EmptyDir.Medium) {
+	case corev1.StorageMediumDefault, corev1.StorageMediumMemory:
+		emptyDir.Medium = corev1.StorageMedium(v.EmptyDir.Medium)
+	default:
+		return corev1.VolumeSource{}, fmt.Errorf("emptyDir medium must be either empty or %s", corev1.StorageMediumMemory)
+	}
+	if len(v.EmptyDir.SizeLimit) > 0 {
+		sizeLimit, err := k8sresource.ParseQuantity(v.EmptyDir.SizeLimit)
+		if err != nil || sizeLimit.Sign() <= 0 {
+			return corev1.VolumeSource{}, fmt.Errorf("emptyDir sizeLimit '%s' must be a positive quantity, such as 1Gi", v.EmptyDir.SizeLimit)
+		}
+		emptyDir.SizeLimit = &sizeLimit
+	}
+	return corev1.VolumeSource{EmptyDir: emptyDir}, nil
+}
+
+// userVolumes adds the volumes declared by the application to the pod, and mounts them.
+// Conflicts with the mounts of the platform are detected when the pod spec is created.
+func userVolumes(ast *resource.Ast, annotations map[string]string) error {
+	value, ok := annotations[VolumesAnnotation]
+	if !ok {
+		return nil
+	}
+
+	volumes := make([]UserVolume, 0)
+	err := yaml.Unmarshal([]byte(value), &volumes)
+	if err != nil {
+		return fmt.Errorf("parse annotation '%s': %s", VolumesAnnotation, err)
+	}
+
+	names := make(map[string]bool)
+	for _, volume := range ast.Volumes {
+		names[volume.Name] = true
+	}
+
+	for _, volume := range volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			return fmt.Errorf("annotation '%s': volume name '%s' is invalid: %s", VolumesAnnotation, volume.Name, errs[0])
+		}
+		if names[volume.Name] {
+			return fmt.Errorf("annotation '%s': volume name '%s' is already in use", VolumesAnnotation, volume.Name)
+		}
+		names[volume.Name] = true
+
+		if !filepath.IsAbs(volume.MountPath) {
+			return fmt.Errorf("annotation '%s': volume '%s': mountPath '%s' must be an absolute path", VolumesAnnotation, volume.Name, volume.MountPath)
+		}
+		source, err := volume.volumeSource()
+		if err != nil {
+			return fmt.Errorf("annotation '%s': volume '%s': %s", VolumesAnnotation, volume.Name, err)
+		}
+
+		mount := corev1.VolumeMount{
+			Name:      volume.Name,
+			MountPath: volume.MountPath,
+			ReadOnly:  volume.ReadOnly,
+		}
+		ast.Volumes = append(ast.Volumes, corev1.Volume{Name: volume.Name, VolumeSource: source})
+		ast.VolumeMounts = append(ast.VolumeMounts, mount)
+		ast.UserVolumeMounts = append(ast.UserVolumeMounts, mount)
+	}
+
+	return nil
+}
+
+// mountPathsOverlap returns true if the paths are equal, or one is inside the other.
+func mountPathsOverlap(a, b string) bool {
+	a, b = filepath.Clean(a), filepath.Clean(b)
+	return a == b || strings.HasPrefix(a, b+"/") || strings.HasPrefix(b, a+"/") || a == "/" || b == "/"
+}
+
+// validateUserVolumeMounts rejects volumes declared by the application that are mounted on top of, or inside,
+// other mounts in the same container, such as the credentials mounted by the platform.
+func validateUserVolumeMounts(podSpec *corev1.PodSpec, userMounts []corev1.VolumeMount) error {
+	isUserMount := make(map[corev1.VolumeMount]bool)
+	for _, mount := range userMounts {
+		isUserMount[mount] = true
+	}
+
+	for _, containers := range [][]corev1.Container{podSpec.InitContainers, podSpec.Containers} {
+		for _, container := range containers {
+			for i, a := range container.VolumeMounts {
+				for _, b := range container.VolumeMounts[i+1:] {
+					if !isUserMount[a] && !isUserMount[b] {
+						continue
+					}
+					if mountPathsOverlap(a.MountPath, b.MountPath) {
+						return fmt.Errorf("annotation '%s': volume '%s' mounted at '%s' conflicts with volume '%s' mounted at '%s'", VolumesAnnotation, a.Name, a.MountPath, b.Name, b.MountPath)
+					}
+				}
+			}
+		}
+	}
+
+	return nil
+}
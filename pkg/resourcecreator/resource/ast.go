@@ -2,10 +2,10 @@ package resource
 
 import (
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func (ast *Ast) AppendOperation(operationType OperationType, resource runtime.Object) {
+func (ast *Ast) AppendOperation(operationType OperationType, resource client.Object) {
 	ast.Operations = append(ast.Operations, Operation{
 		Operation: operationType,
 		Resource:  resource,
@@ -24,6 +24,9 @@ type Ast struct {
 	Labels         map[string]string
 	Volumes        []corev1.Volume
 	VolumeMounts   []corev1.VolumeMount
+
+	// Volume mounts declared by the application, which must not conflict with those of the platform.
+	UserVolumeMounts []corev1.VolumeMount
 }
 
 func NewAst() *Ast {
@@ -2,32 +2,99 @@ package resource
 
 import (
 	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/util"
 )
 
 // Options defines customizations for resource objects.
 type Options struct {
 	AccessPolicyNotAllowedCIDRs       []string
+	AllowedNginxAnnotations           []string
 	ApiServerIp                       string
 	AzureratorEnabled                 bool
+	CertManagerEnabled                bool
+	CertManager                       config.CertManager
 	ClusterName                       string
 	DigdiratorEnabled                 bool
 	DigdiratorHosts                   []string
+	ExternalHostAddresses             map[string][]string
+	GatewayAPI                        bool
 	GatewayMappings                   []config.GatewayMapping
 	GoogleCloudSQLProxyContainerImage string
 	GoogleProjectId                   string
 	GoogleTeamProjectId               string
+	GoogleTeamProjectNumber           string
 	HostAliases                       []config.HostAlias
+	IngressV1beta1                    bool
 	JwkerEnabled                      bool
 	NetworkPolicy                     bool
+	NetworkPolicyPlatform             config.NetworkPolicy
 	KafkaratorEnabled                 bool
 	KafkaratorSecretName              string
+	KedaEnabled                       bool
+	Keda                              config.Keda
 	Linkerd                           bool
+	LinkerdPolicyEnabled              bool
+	LinkerdPolicy                     config.LinkerdPolicy
 	NativeSecrets                     bool
 	NumReplicas                       int32
 	Proxy                             config.Proxy
+	RedisHost                         string
+	RedisPort                         int
+	ScaledDown                        bool
+	ScalingScheduleEnabled            bool
+	ScalingSchedule                   config.ScalingSchedule
+	SecretManagerEnabled              bool
 	Securelogs                        config.Securelogs
+	SecurityBaselineEnabled           bool
+	SecurityBaseline                  config.SecurityBaseline
+	StatefulSetEnabled                bool
+	StatefulSet                       bool
+	StrictEgressEnabled               bool
+	StrictEgress                      config.StrictEgress
+	Topology                          config.Topology
 	VaultEnabled                      bool
 	Vault                             config.Vault
+	VerticalPodAutoscalerEnabled      bool
+	VerticalPodAutoscaler             config.VerticalPodAutoscaler
+}
+
+// Kinds of workloads rendered for applications.
+const (
+	WorkloadDeployment  = "Deployment"
+	WorkloadStatefulSet = "StatefulSet"
+)
+
+// WorkloadKind returns the kind of workload rendered for the application, which autoscalers must target.
+func (o Options) WorkloadKind() string {
+	if o.StatefulSet {
+		return WorkloadStatefulSet
+	}
+	return WorkloadDeployment
+}
+
+// CustomDomain returns true if a host is not covered by any gateway mapping, and is served with a certificate
+// issued specifically for that host by cert-manager.
+func (o Options) CustomDomain(host string) bool {
+	return o.CertManagerEnabled && util.ResolveIngressClass(host, o.GatewayMappings) == nil
+}
+
+// ResolveIngressClass returns the ingress class serving a host.
+// Hosts matching a gateway mapping are served by the mapped ingress class, which carries a wildcard certificate.
+// Custom domains are served by the cert-manager ingress class.
+func (o Options) ResolveIngressClass(host string) (ingressClass *string, customDomain bool) {
+	if o.CustomDomain(host) {
+		return &o.CertManager.IngressClass, true
+	}
+	return util.ResolveIngressClass(host, o.GatewayMappings), false
+}
+
+// ResolveGateway returns the Gateway API Gateway serving a host.
+// Custom domains are served by the cert-manager gateway.
+func (o Options) ResolveGateway(host string) (gateway *config.Gateway, customDomain bool) {
+	if o.CustomDomain(host) {
+		return &o.CertManager.Gateway, true
+	}
+	return util.ResolveGateway(host, o.GatewayMappings), false
 }
 
 // NewOptions creates a struct with the default resource options.
@@ -48,17 +48,22 @@ func CreateObjectMeta(source Source) metav1.ObjectMeta {
 // We concatenate name, namespace and add a hash in order to avoid duplicate names when creating service accounts in common service accounts namespace.
 // Also making sure to not exceed name length restrictions of 30 characters
 func CreateAppNamespaceHash(source Source) string {
-	name := source.GetName()
-	namespace := source.GetNamespace()
-	if len(name) > 11 {
-		name = source.GetName()[:11]
+	return AppNamespaceHash(source.GetName(), source.GetNamespace())
+}
+
+// AppNamespaceHash is CreateAppNamespaceHash for applications other than the one being synchronized.
+func AppNamespaceHash(name, namespace string) string {
+	shortName := name
+	shortNamespace := namespace
+	if len(shortName) > 11 {
+		shortName = shortName[:11]
 	}
-	if len(namespace) > 10 {
-		namespace = source.GetNamespace()[:10]
+	if len(shortNamespace) > 10 {
+		shortNamespace = shortNamespace[:10]
 	}
-	appNameSpace := name + "-" + namespace
+	appNameSpace := shortName + "-" + shortNamespace
 
-	checksum := crc32.ChecksumIEEE([]byte(source.GetName() + "-" + source.GetNamespace()))
+	checksum := crc32.ChecksumIEEE([]byte(name + "-" + namespace))
 	bs := make([]byte, 4)
 	binary.LittleEndian.PutUint32(bs, checksum)
 
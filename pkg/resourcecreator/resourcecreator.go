@@ -16,11 +16,14 @@ import (
 	"github.com/nais/naiserator/pkg/resourcecreator/certificateauthority"
 	"github.com/nais/naiserator/pkg/resourcecreator/deployment"
 	"github.com/nais/naiserator/pkg/resourcecreator/google/gcp"
+	"github.com/nais/naiserator/pkg/resourcecreator/google/redis"
+	"github.com/nais/naiserator/pkg/resourcecreator/google/secretmanager"
 	"github.com/nais/naiserator/pkg/resourcecreator/horizontalpodautoscaler"
 	"github.com/nais/naiserator/pkg/resourcecreator/idporten"
 	"github.com/nais/naiserator/pkg/resourcecreator/ingress"
 	"github.com/nais/naiserator/pkg/resourcecreator/jwker"
 	"github.com/nais/naiserator/pkg/resourcecreator/kafka"
+	"github.com/nais/naiserator/pkg/resourcecreator/keda"
 	"github.com/nais/naiserator/pkg/resourcecreator/leaderelection"
 	"github.com/nais/naiserator/pkg/resourcecreator/linkerd"
 	"github.com/nais/naiserator/pkg/resourcecreator/maskinporten"
@@ -29,10 +32,13 @@ import (
 	"github.com/nais/naiserator/pkg/resourcecreator/poddisruptionbudget"
 	"github.com/nais/naiserator/pkg/resourcecreator/proxyopts"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/resourcecreator/scalingschedule"
 	"github.com/nais/naiserator/pkg/resourcecreator/securelogs"
 	"github.com/nais/naiserator/pkg/resourcecreator/service"
 	"github.com/nais/naiserator/pkg/resourcecreator/serviceaccount"
+	"github.com/nais/naiserator/pkg/resourcecreator/statefulset"
 	"github.com/nais/naiserator/pkg/resourcecreator/vault"
+	"github.com/nais/naiserator/pkg/resourcecreator/verticalpodautoscaler"
 )
 
 // CreateApplication takes an Application resource and returns a slice of Kubernetes resources
@@ -43,13 +49,29 @@ func CreateApplication(app *nais_io_v1alpha1.Application, resourceOptions resour
 		return nil, fmt.Errorf("the 'team' label needs to be set in the application metadata")
 	}
 
+	statefulSet, err := statefulset.Parse(app.Annotations, resourceOptions)
+	if err != nil {
+		return nil, err
+	}
+	resourceOptions.StatefulSet = statefulSet != nil
+
 	ast := resource.NewAst()
 
 	service.Create(app, ast, *app.Spec.Service)
 	serviceaccount.Create(app, ast, resourceOptions)
-	horizontalpodautoscaler.Create(app, ast, *app.Spec.Replicas)
-	networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
-	err := ingress.Create(app, ast, resourceOptions, app.Spec.Ingresses, app.Spec.Liveness.Path, app.Spec.Service.Protocol, app.Annotations)
+	err = createAutoscaler(app, ast, resourceOptions)
+	if err != nil {
+		return nil, err
+	}
+	err = verticalpodautoscaler.Create(app, ast, resourceOptions, *app.Spec.Replicas, app.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	err = networkpolicy.Create(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Ingresses, app.Spec.LeaderElection)
+	if err != nil {
+		return nil, err
+	}
+	err = ingress.Create(app, ast, resourceOptions, app.Spec.Ingresses, app.Spec.Liveness.Path, app.Spec.Service.Protocol, app.Annotations)
 	if err != nil {
 		return nil, err
 	}
@@ -63,7 +85,15 @@ func CreateApplication(app *nais_io_v1alpha1.Application, resourceOptions resour
 		return nil, err
 	}
 	kafka.Create(app, ast, resourceOptions, app.Spec.Kafka)
-	err = gcp.Create(app, ast, resourceOptions, app.Spec.GCP)
+	err = gcp.Create(app, ast, resourceOptions, app.Spec.GCP, *app.Spec.AccessPolicy)
+	if err != nil {
+		return nil, err
+	}
+	err = google_secretmanager.Create(app, ast, resourceOptions)
+	if err != nil {
+		return nil, err
+	}
+	err = google_redis.Create(app, ast, resourceOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -77,11 +107,14 @@ func CreateApplication(app *nais_io_v1alpha1.Application, resourceOptions resour
 	if err != nil {
 		return nil, err
 	}
-	poddisruptionbudget.Create(app, ast, *app.Spec.Replicas)
+	if !resourceOptions.ScaledDown {
+		poddisruptionbudget.Create(app, ast, *app.Spec.Replicas)
+	}
 	jwker.Create(app, ast, resourceOptions, *app.Spec.TokenX, app.Spec.AccessPolicy)
 	aiven.Elastic(ast, app.Spec.Elastic)
 	aiven.Influx(ast, app.Spec.Influx)
 	linkerd.Create(ast, resourceOptions)
+	linkerd.CreatePolicy(app, ast, resourceOptions, *app.Spec.AccessPolicy, app.Spec.Service.Protocol)
 
 	err = vault.Create(app, ast, resourceOptions, app.Spec.Vault)
 	if err != nil {
@@ -93,7 +126,16 @@ func CreateApplication(app *nais_io_v1alpha1.Application, resourceOptions resour
 		return nil, err
 	}
 
-	err = deployment.Create(app, ast, resourceOptions)
+	err = pod.CreateUserContainers(app.Name, ast, app.Annotations)
+	if err != nil {
+		return nil, err
+	}
+
+	if statefulSet != nil {
+		err = statefulset.Create(app, ast, resourceOptions, statefulSet)
+	} else {
+		err = deployment.Create(app, ast, resourceOptions)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -101,6 +143,34 @@ func CreateApplication(app *nais_io_v1alpha1.Application, resourceOptions resour
 	return ast.Operations, nil
 }
 
+// createAutoscaler creates either a HorizontalPodAutoscaler or a KEDA ScaledObject for the application.
+// No autoscaler is created while the application is scaled down outside of its scaling schedule.
+func createAutoscaler(app *nais_io_v1alpha1.Application, ast *resource.Ast, resourceOptions resource.Options) error {
+	_, scheduled := app.Annotations[scalingschedule.Annotation]
+	if scheduled && !resourceOptions.ScalingScheduleEnabled {
+		return fmt.Errorf("annotation '%s' is not supported in this cluster", scalingschedule.Annotation)
+	}
+
+	kedaAnnotation, ok := app.Annotations[keda.Annotation]
+	if ok {
+		if _, ok := app.Annotations[horizontalpodautoscaler.MetricsAnnotation]; ok {
+			return fmt.Errorf("annotation '%s' cannot be combined with '%s'", horizontalpodautoscaler.MetricsAnnotation, keda.Annotation)
+		}
+		if scheduled {
+			return fmt.Errorf("annotation '%s' cannot be combined with '%s'; use cron triggers instead", scalingschedule.Annotation, keda.Annotation)
+		}
+	}
+
+	switch {
+	case resourceOptions.ScaledDown:
+		return nil
+	case ok:
+		return keda.Create(app, ast, resourceOptions, *app.Spec.Replicas, app.Spec.Kafka, kedaAnnotation)
+	default:
+		return horizontalpodautoscaler.Create(app, ast, resourceOptions.WorkloadKind(), *app.Spec.Replicas, app.Annotations)
+	}
+}
+
 // CreateNaisjob takes an Naisjob resource and returns a slice of Kubernetes resources
 // along with information about what to do with these resources.
 func CreateNaisjob(naisjob *nais_io_v1.Naisjob, resourceOptions resource.Options) (resource.Operations, error) {
@@ -112,13 +182,20 @@ func CreateNaisjob(naisjob *nais_io_v1.Naisjob, resourceOptions resource.Options
 	ast := resource.NewAst()
 
 	serviceaccount.Create(naisjob, ast, resourceOptions)
-	networkpolicy.Create(naisjob, ast, resourceOptions, *naisjob.Spec.AccessPolicy, []nais_io_v1.Ingress{}, false)
-	err := azure.Create(naisjob, ast, resourceOptions, *naisjob.Spec.Azure, []nais_io_v1.Ingress{}, *naisjob.Spec.AccessPolicy)
+	err := networkpolicy.Create(naisjob, ast, resourceOptions, *naisjob.Spec.AccessPolicy, []nais_io_v1.Ingress{}, false)
+	if err != nil {
+		return nil, err
+	}
+	err = azure.Create(naisjob, ast, resourceOptions, *naisjob.Spec.Azure, []nais_io_v1.Ingress{}, *naisjob.Spec.AccessPolicy)
 	if err != nil {
 		return nil, err
 	}
 	kafka.Create(naisjob, ast, resourceOptions, naisjob.Spec.Kafka)
-	err = gcp.Create(naisjob, ast, resourceOptions, naisjob.Spec.GCP)
+	err = gcp.Create(naisjob, ast, resourceOptions, naisjob.Spec.GCP, *naisjob.Spec.AccessPolicy)
+	if err != nil {
+		return nil, err
+	}
+	err = google_secretmanager.Create(naisjob, ast, resourceOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -9,7 +9,7 @@ import (
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
 	sql_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/sql.cnrm.cloud.google.com/v1beta1"
-	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
+	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
 	"github.com/nais/naiserator/pkg/naiserator/config"
 	"github.com/nais/naiserator/pkg/resourcecreator"
 	"github.com/nais/naiserator/pkg/resourcecreator/google"
@@ -18,16 +18,17 @@ import (
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscaling "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	core "k8s.io/api/core/v1"
 	networking "k8s.io/api/networking/v1"
-	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbac "k8s.io/api/rbac/v1"
 )
 
 type realObjects struct {
 	deployment              *appsv1.Deployment
 	hpa                     *autoscaling.HorizontalPodAutoscaler
-	ingress                 *networkingv1beta1.Ingress
+	ingress                 *networking.Ingress
 	jwker                   *nais_io_v1.Jwker
 	networkPolicy           *networking.NetworkPolicy
 	role                    *rbac.Role
@@ -58,7 +59,7 @@ func getRealObjects(resources resource.Operations) (o realObjects) {
 			o.serviceAccount = v
 		case *autoscaling.HorizontalPodAutoscaler:
 			o.hpa = v
-		case *networkingv1beta1.Ingress:
+		case *networking.Ingress:
 			o.ingress = v
 		case *networking.NetworkPolicy:
 			o.networkPolicy = v
@@ -318,4 +319,25 @@ func TestCreate(t *testing.T) {
 		_, err = resourcecreator.CreateApplication(app, opts)
 		assert.NoError(t, err, "should not return error if redirect URI is subpath of ingress")
 	})
+
+	t.Run("no autoscaler or disruption budget is created while scaled down by the scaling schedule", func(t *testing.T) {
+		app := fixtures.MinimalApplication()
+		err := app.ApplyDefaults()
+		assert.NoError(t, err)
+
+		opts := resource.NewOptions()
+		opts.ScaledDown = true
+		opts.NumReplicas = 0
+
+		resources, err := resourcecreator.CreateApplication(app, opts)
+		assert.NoError(t, err)
+
+		for _, r := range resources {
+			switch r.Resource.(type) {
+			case *autoscalingv2beta2.HorizontalPodAutoscaler, *policyv1beta1.PodDisruptionBudget:
+				t.Errorf("unexpected %T while scaled down", r.Resource)
+			}
+		}
+		assert.Equal(t, int32(0), *getRealObjects(resources).deployment.Spec.Replicas)
+	})
 }
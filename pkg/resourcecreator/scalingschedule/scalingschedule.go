@@ -0,0 +1,204 @@
+package scalingschedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+)
+
+// Applications declare when they need to run with this annotation. Outside of the scheduled windows,
+// the autoscaler is removed and the Deployment is scaled down to the given number of replicas, e.g.
+//
+//	nais.io/scaling-schedule: |
+//	  timezone: Europe/Oslo
+//	  replicas: 0
+//	  windows:
+//	    - days: [mon, tue, wed, thu, fri]
+//	      start: "07:00"
+//	      end: "17:00"
+const Annotation = "nais.io/scaling-schedule"
+
+// ScaleUpUntilAnnotation scales an application up outside of its scheduled windows until the given RFC 3339 time, e.g.
+//
+//	nais.io/scale-up-until: "2021-06-01T22:00:00+02:00"
+const ScaleUpUntilAnnotation = "nais.io/scale-up-until"
+
+const timeLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+type Window struct {
+	Days  []string `json:"days"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+}
+
+type Schedule struct {
+	Timezone string   `json:"timezone"`
+	Replicas *int     `json:"replicas,omitempty"`
+	Windows  []Window `json:"windows"`
+
+	location     *time.Location
+	scaleUpUntil time.Time
+}
+
+// State is the result of evaluating a schedule at a point in time.
+type State struct {
+	ScaledDown bool
+	Replicas   int
+	// Next is the time at which the state may change.
+	Next time.Time
+}
+
+// Parse reads the scaling schedule from the annotations of an application.
+// A nil schedule is returned if the application has no schedule.
+func Parse(annotations map[string]string) (*Schedule, error) {
+	value, ok := annotations[Annotation]
+	if !ok {
+		return nil, nil
+	}
+
+	schedule := &Schedule{}
+	err := yaml.Unmarshal([]byte(value), schedule)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	if until, ok := annotations[ScaleUpUntilAnnotation]; ok {
+		schedule.scaleUpUntil, err = time.Parse(time.RFC3339, until)
+		if err != nil {
+			return nil, fmt.Errorf("parse annotation '%s': %s", ScaleUpUntilAnnotation, err)
+		}
+	}
+
+	return schedule, nil
+}
+
+func (w Window) validate() error {
+	if len(w.Days) == 0 {
+		return fmt.Errorf("at least one day is required")
+	}
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("unknown day '%s'; use one of mon, tue, wed, thu, fri, sat or sun", day)
+		}
+	}
+	start, err := time.Parse(timeLayout, w.Start)
+	if err != nil {
+		return fmt.Errorf("start '%s' is not a time of day on the form HH:MM", w.Start)
+	}
+	end, err := time.Parse(timeLayout, w.End)
+	if err != nil {
+		return fmt.Errorf("end '%s' is not a time of day on the form HH:MM", w.End)
+	}
+	if !end.After(start) {
+		return fmt.Errorf("end must be later than start on the same day")
+	}
+	return nil
+}
+
+// Validate rejects schedules that cannot be evaluated, or that scale below what the cluster allows.
+func (s *Schedule) Validate(scalingSchedule config.ScalingSchedule, maxReplicas int) error {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil || len(s.Timezone) == 0 {
+		return fmt.Errorf("timezone '%s' is not a valid IANA time zone", s.Timezone)
+	}
+	s.location = location
+
+	if len(s.Windows) == 0 {
+		return fmt.Errorf("at least one window is required")
+	}
+	for i, window := range s.Windows {
+		err = window.validate()
+		if err != nil {
+			return fmt.Errorf("window %d: %s", i+1, err)
+		}
+	}
+
+	if s.Replicas != nil {
+		if *s.Replicas == 0 && !scalingSchedule.ScaleToZero {
+			return fmt.Errorf("scaling to zero replicas is not allowed in this cluster")
+		}
+		if *s.Replicas < 0 || *s.Replicas > maxReplicas {
+			return fmt.Errorf("replicas must be between 0 and replicas.max (%d)", maxReplicas)
+		}
+	}
+
+	return nil
+}
+
+// replicas returns the number of replicas outside of the scheduled windows.
+func (s *Schedule) replicas(scalingSchedule config.ScalingSchedule) int {
+	if s.Replicas != nil {
+		return *s.Replicas
+	}
+	if scalingSchedule.ScaleToZero {
+		return 0
+	}
+	return 1
+}
+
+// transitions returns the start and end times of all windows on the given day.
+func (s *Schedule) transitions(day time.Time) [][2]time.Time {
+	transitions := make([][2]time.Time, 0, len(s.Windows))
+	for _, window := range s.Windows {
+		for _, name := range window.Days {
+			if weekdays[strings.ToLower(name)] != day.Weekday() {
+				continue
+			}
+			start, _ := time.Parse(timeLayout, window.Start)
+			end, _ := time.Parse(timeLayout, window.End)
+			transitions = append(transitions, [2]time.Time{
+				time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), 0, 0, s.location),
+				time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, s.location),
+			})
+		}
+	}
+	return transitions
+}
+
+// Evaluate determines whether the application should be scaled down at the given time, and when to evaluate again.
+// The schedule must be validated first.
+func (s *Schedule) Evaluate(scalingSchedule config.ScalingSchedule, now time.Time) State {
+	state := State{
+		ScaledDown: true,
+		Replicas:   s.replicas(scalingSchedule),
+	}
+
+	local := now.In(s.location)
+	today := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, s.location)
+
+	// Windows never span midnight, so a week ahead always contains the next transition.
+	for days := 0; days <= 7; days++ {
+		for _, window := range s.transitions(today.AddDate(0, 0, days)) {
+			if !now.Before(window[0]) && now.Before(window[1]) {
+				state.ScaledDown = false
+			}
+			for _, transition := range window {
+				if transition.After(now) && (state.Next.IsZero() || transition.Before(state.Next)) {
+					state.Next = transition
+				}
+			}
+		}
+	}
+
+	if now.Before(s.scaleUpUntil) {
+		state.ScaledDown = false
+		if s.scaleUpUntil.Before(state.Next) {
+			state.Next = s.scaleUpUntil
+		}
+	}
+
+	return state
+}
@@ -0,0 +1,98 @@
+package scalingschedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/scalingschedule"
+	"github.com/stretchr/testify/assert"
+)
+
+const officeHours = `
+timezone: Europe/Oslo
+windows:
+  - days: [mon, tue, wed, thu, fri]
+    start: "07:00"
+    end: "17:00"
+`
+
+func schedule(t *testing.T, annotations map[string]string, cfg config.ScalingSchedule) *scalingschedule.Schedule {
+	s, err := scalingschedule.Parse(annotations)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Validate(cfg, 4))
+	return s
+}
+
+func TestEvaluate(t *testing.T) {
+	oslo, err := time.LoadLocation("Europe/Oslo")
+	assert.NoError(t, err)
+	cfg := config.ScalingSchedule{ScaleToZero: true}
+
+	t.Run("within office hours", func(t *testing.T) {
+		// Wednesday
+		state := schedule(t, map[string]string{scalingschedule.Annotation: officeHours}, cfg).Evaluate(cfg, time.Date(2021, 6, 2, 12, 0, 0, 0, oslo))
+		assert.False(t, state.ScaledDown)
+		assert.Equal(t, time.Date(2021, 6, 2, 17, 0, 0, 0, oslo), state.Next)
+	})
+
+	t.Run("friday evening scales down until monday morning", func(t *testing.T) {
+		state := schedule(t, map[string]string{scalingschedule.Annotation: officeHours}, cfg).Evaluate(cfg, time.Date(2021, 6, 4, 17, 0, 0, 0, oslo))
+		assert.True(t, state.ScaledDown)
+		assert.Equal(t, 0, state.Replicas)
+		assert.Equal(t, time.Date(2021, 6, 7, 7, 0, 0, 0, oslo), state.Next)
+	})
+
+	t.Run("one replica when scaling to zero is not allowed", func(t *testing.T) {
+		cfg := config.ScalingSchedule{}
+		state := schedule(t, map[string]string{scalingschedule.Annotation: officeHours}, cfg).Evaluate(cfg, time.Date(2021, 6, 5, 12, 0, 0, 0, oslo))
+		assert.True(t, state.ScaledDown)
+		assert.Equal(t, 1, state.Replicas)
+	})
+
+	t.Run("scaled up on demand", func(t *testing.T) {
+		s := schedule(t, map[string]string{
+			scalingschedule.Annotation:             officeHours,
+			scalingschedule.ScaleUpUntilAnnotation: "2021-06-05T14:00:00+02:00",
+		}, cfg)
+
+		state := s.Evaluate(cfg, time.Date(2021, 6, 5, 12, 0, 0, 0, oslo))
+		assert.False(t, state.ScaledDown)
+		assert.Equal(t, time.Date(2021, 6, 5, 14, 0, 0, 0, oslo), state.Next.In(oslo))
+
+		state = s.Evaluate(cfg, time.Date(2021, 6, 5, 14, 0, 0, 0, oslo))
+		assert.True(t, state.ScaledDown)
+		assert.Equal(t, time.Date(2021, 6, 7, 7, 0, 0, 0, oslo), state.Next)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		value string
+		err   string
+	}{
+		{"missing timezone", `{windows: [{days: [mon], start: "07:00", end: "17:00"}]}`, "timezone '' is not a valid IANA time zone"},
+		{"unknown timezone", `{timezone: Mars/Olympus, windows: [{days: [mon], start: "07:00", end: "17:00"}]}`, "timezone 'Mars/Olympus' is not a valid IANA time zone"},
+		{"no windows", `{timezone: UTC}`, "at least one window is required"},
+		{"unknown day", `{timezone: UTC, windows: [{days: [monday], start: "07:00", end: "17:00"}]}`, "window 1: unknown day 'monday'; use one of mon, tue, wed, thu, fri, sat or sun"},
+		{"invalid time", `{timezone: UTC, windows: [{days: [mon], start: "7", end: "17:00"}]}`, "window 1: start '7' is not a time of day on the form HH:MM"},
+		{"window spans midnight", `{timezone: UTC, windows: [{days: [mon], start: "22:00", end: "02:00"}]}`, "window 1: end must be later than start on the same day"},
+		{"zero replicas", `{timezone: UTC, replicas: 0, windows: [{days: [mon], start: "07:00", end: "17:00"}]}`, "scaling to zero replicas is not allowed in this cluster"},
+		{"too many replicas", `{timezone: UTC, replicas: 5, windows: [{days: [mon], start: "07:00", end: "17:00"}]}`, "replicas must be between 0 and replicas.max (4)"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := scalingschedule.Parse(map[string]string{scalingschedule.Annotation: test.value})
+			assert.NoError(t, err)
+			assert.EqualError(t, s.Validate(config.ScalingSchedule{}, 4), test.err)
+		})
+	}
+
+	t.Run("invalid scale up time", func(t *testing.T) {
+		_, err := scalingschedule.Parse(map[string]string{
+			scalingschedule.Annotation:             officeHours,
+			scalingschedule.ScaleUpUntilAnnotation: "tomorrow",
+		})
+		assert.EqualError(t, err, `parse annotation 'nais.io/scale-up-until': parsing time "tomorrow" as "2006-01-02T15:04:05Z07:00": cannot parse "tomorrow" as "2006"`)
+	})
+}
@@ -0,0 +1,260 @@
+package statefulset
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/ghodss/yaml"
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	"github.com/nais/naiserator/pkg/resourcecreator/pod"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Applications that need a stable identity or persistent disks run as a StatefulSet with this annotation, e.g.
+//
+//	nais.io/statefulset: |
+//	  podManagementPolicy: Parallel
+//	  volumeClaims:
+//	    - name: data
+//	      mountPath: /var/lib/data
+//	      size: 10Gi
+//	      storageClassName: ssd
+//
+// The access mode of volume claims defaults to ReadWriteOnce. Volume claims cannot be changed once the StatefulSet
+// exists. Persistent volume claims are kept when the application is deleted, or runs as a Deployment again.
+const Annotation = "nais.io/statefulset"
+
+// HeadlessServiceSuffix is appended to the application name to form the name of the headless Service,
+// which gives each replica a stable DNS name.
+const HeadlessServiceSuffix = "-headless"
+
+type VolumeClaim struct {
+	Name             string `json:"name"`
+	MountPath        string `json:"mountPath"`
+	Size             string `json:"size"`
+	StorageClassName string `json:"storageClassName,omitempty"`
+	AccessMode       string `json:"accessMode,omitempty"`
+}
+
+type StatefulSet struct {
+	// Either OrderedReady or Parallel; defaults to OrderedReady.
+	PodManagementPolicy string        `json:"podManagementPolicy,omitempty"`
+	VolumeClaims        []VolumeClaim `json:"volumeClaims,omitempty"`
+}
+
+// Parse reads the StatefulSet settings from the annotations of an application.
+// A nil StatefulSet is returned if the application runs as a Deployment.
+func Parse(annotations map[string]string, resourceOptions resource.Options) (*StatefulSet, error) {
+	value, ok := annotations[Annotation]
+	if !ok {
+		return nil, nil
+	}
+	if !resourceOptions.StatefulSetEnabled {
+		return nil, fmt.Errorf("annotation '%s' is not supported in this cluster", Annotation)
+	}
+
+	statefulSet := &StatefulSet{}
+	err := yaml.Unmarshal([]byte(value), statefulSet)
+	if err != nil {
+		return nil, fmt.Errorf("parse annotation '%s': %s", Annotation, err)
+	}
+
+	err = statefulSet.validate()
+	if err != nil {
+		return nil, fmt.Errorf("annotation '%s': %s", Annotation, err)
+	}
+
+	return statefulSet, nil
+}
+
+func (c VolumeClaim) validate() error {
+	if errs := validation.IsDNS1123Label(c.Name); len(errs) > 0 {
+		return fmt.Errorf("name '%s' is invalid: %s", c.Name, errs[0])
+	}
+	if !filepath.IsAbs(c.MountPath) {
+		return fmt.Errorf("mountPath '%s' must be an absolute path", c.MountPath)
+	}
+	quantity, err := k8sresource.ParseQuantity(c.Size)
+	if err != nil || quantity.Sign() <= 0 {
+		return fmt.Errorf("size '%s' must be a positive quantity, such as 10Gi", c.Size)
+	}
+	switch corev1.PersistentVolumeAccessMode(c.AccessMode) {
+	case "", corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany:
+	default:
+		return fmt.Errorf("accessMode must be one of %s, %s or %s", corev1.ReadWriteOnce, corev1.ReadOnlyMany, corev1.ReadWriteMany)
+	}
+	return nil
+}
+
+func (s *StatefulSet) validate() error {
+	switch appsv1.PodManagementPolicyType(s.PodManagementPolicy) {
+	case "", appsv1.OrderedReadyPodManagement, appsv1.ParallelPodManagement:
+	default:
+		return fmt.Errorf("podManagementPolicy must be either %s or %s", appsv1.OrderedReadyPodManagement, appsv1.ParallelPodManagement)
+	}
+
+	names := make(map[string]bool)
+	mountPaths := make(map[string]bool)
+	for i, claim := range s.VolumeClaims {
+		err := claim.validate()
+		if err != nil {
+			return fmt.Errorf("volume claim %d: %s", i+1, err)
+		}
+		if names[claim.Name] {
+			return fmt.Errorf("volume claim %d: name '%s' is used more than once", i+1, claim.Name)
+		}
+		if mountPaths[filepath.Clean(claim.MountPath)] {
+			return fmt.Errorf("volume claim %d: mountPath '%s' is used more than once", i+1, claim.MountPath)
+		}
+		names[claim.Name] = true
+		mountPaths[filepath.Clean(claim.MountPath)] = true
+	}
+
+	return nil
+}
+
+func (s *StatefulSet) volumeClaimTemplates(app *nais_io_v1alpha1.Application) []corev1.PersistentVolumeClaim {
+	templates := make([]corev1.PersistentVolumeClaim, 0, len(s.VolumeClaims))
+	for _, claim := range s.VolumeClaims {
+		accessMode := corev1.PersistentVolumeAccessMode(claim.AccessMode)
+		if len(accessMode) == 0 {
+			accessMode = corev1.ReadWriteOnce
+		}
+		template := corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: claim.Name,
+				Labels: map[string]string{
+					"app":  app.Name,
+					"team": app.Labels["team"],
+				},
+			},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				AccessModes: []corev1.PersistentVolumeAccessMode{accessMode},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceStorage: k8sresource.MustParse(claim.Size),
+					},
+				},
+			},
+		}
+		if len(claim.StorageClassName) > 0 {
+			template.Spec.StorageClassName = &claim.StorageClassName
+		}
+		templates = append(templates, template)
+	}
+	return templates
+}
+
+// mountVolumeClaims mounts the persistent volumes in the application container.
+func (s *StatefulSet) mountVolumeClaims(podSpec *corev1.PodSpec, appName string) {
+	for i, container := range podSpec.Containers {
+		if container.Name != appName {
+			continue
+		}
+		// The mounts of the application container are shared with the Ast, so they must be copied before appending.
+		mounts := make([]corev1.VolumeMount, 0, len(container.VolumeMounts)+len(s.VolumeClaims))
+		mounts = append(mounts, container.VolumeMounts...)
+		for _, claim := range s.VolumeClaims {
+			mounts = append(mounts, corev1.VolumeMount{
+				Name:      claim.Name,
+				MountPath: claim.MountPath,
+			})
+		}
+		podSpec.Containers[i].VolumeMounts = mounts
+	}
+}
+
+func headlessService(app *nais_io_v1alpha1.Application) *corev1.Service {
+	objectMeta := resource.CreateObjectMeta(app)
+	objectMeta.Name = app.Name + HeadlessServiceSuffix
+
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Service",
+			APIVersion: "v1",
+		},
+		ObjectMeta: objectMeta,
+		Spec: corev1.ServiceSpec{
+			Type:      corev1.ServiceTypeClusterIP,
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  map[string]string{"app": app.Name},
+			// Replicas must be able to find each other before they are ready.
+			PublishNotReadyAddresses: true,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       nais_io_v1alpha1.DefaultPortName,
+					Protocol:   corev1.ProtocolTCP,
+					Port:       int32(app.Spec.Port),
+					TargetPort: intstr.FromString(nais_io_v1alpha1.DefaultPortName),
+				},
+			},
+		},
+	}
+}
+
+// Create renders a StatefulSet and its headless Service for the application, instead of a Deployment.
+func Create(app *nais_io_v1alpha1.Application, ast *resource.Ast, resourceOptions resource.Options, statefulSet *StatefulSet) error {
+	podSpec, err := pod.CreateSpec(ast, resourceOptions, app.Name, corev1.RestartPolicyAlways)
+	if err != nil {
+		return fmt.Errorf("create statefulset: %w", err)
+	}
+
+	statefulSet.mountVolumeClaims(podSpec, app.Name)
+
+	err = pod.SetTopologySpread(podSpec, resourceOptions, app.Name, app.Annotations)
+	if err != nil {
+		return fmt.Errorf("create statefulset: %w", err)
+	}
+
+	err = pod.SetSecurityContext(podSpec, ast, resourceOptions, app.Name, app.Annotations)
+	if err != nil {
+		return fmt.Errorf("create statefulset: %w", err)
+	}
+
+	objectMeta := resource.CreateObjectMeta(app)
+	if val, ok := app.Annotations["kubernetes.io/change-cause"]; ok {
+		objectMeta.Annotations["kubernetes.io/change-cause"] = val
+	}
+
+	podManagementPolicy := appsv1.PodManagementPolicyType(statefulSet.PodManagementPolicy)
+	if len(podManagementPolicy) == 0 {
+		podManagementPolicy = appsv1.OrderedReadyPodManagement
+	}
+
+	sts := &appsv1.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "StatefulSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: objectMeta,
+		Spec: appsv1.StatefulSetSpec{
+			Replicas: util.Int32p(resourceOptions.NumReplicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": app.Name},
+			},
+			ServiceName:         app.Name + HeadlessServiceSuffix,
+			PodManagementPolicy: podManagementPolicy,
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{
+				Type: appsv1.RollingUpdateStatefulSetStrategyType,
+			},
+			RevisionHistoryLimit: util.Int32p(10),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: pod.CreateAppObjectMeta(app, ast),
+				Spec:       *podSpec,
+			},
+			VolumeClaimTemplates: statefulSet.volumeClaimTemplates(app),
+		},
+	}
+
+	ast.AppendOperation(resource.OperationCreateOrUpdate, headlessService(app))
+	ast.AppendOperation(resource.OperationCreateOrUpdate, sts)
+
+	return nil
+}
@@ -0,0 +1,209 @@
+package verticalpodautoscaler
+
+import (
+	"fmt"
+	"sort"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	autoscaling_k8s_io_v1 "github.com/nais/naiserator/pkg/apis/autoscaling.k8s.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/keda"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Applications have resource recommendations applied automatically with this annotation, where the cluster allows it:
+//
+//	nais.io/vertical-pod-autoscaler: auto
+//
+// Otherwise, recommendations are only reported.
+const ModeAnnotation = "nais.io/vertical-pod-autoscaler"
+
+// Modes supported in the annotation.
+const (
+	ModeOff  = "off"
+	ModeAuto = "auto"
+)
+
+// bounds returns the resources configured for the cluster, ignoring those that are not set.
+func bounds(resources map[corev1.ResourceName]string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	for name, value := range resources {
+		if len(value) == 0 {
+			continue
+		}
+		quantity, err := k8sresource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s bound '%s': %s", name, value, err)
+		}
+		list[name] = quantity
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list, nil
+}
+
+// containerPolicies applies recommendations to the application container only, within the cluster bounds.
+// Sidecars keep the resources they are created with.
+func containerPolicies(source resource.Source, cfg config.VerticalPodAutoscaler) ([]autoscaling_k8s_io_v1.ContainerResourcePolicy, error) {
+	minAllowed, err := bounds(map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    cfg.MinCPU,
+		corev1.ResourceMemory: cfg.MinMemory,
+	})
+	if err != nil {
+		return nil, err
+	}
+	maxAllowed, err := bounds(map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    cfg.MaxCPU,
+		corev1.ResourceMemory: cfg.MaxMemory,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	auto := autoscaling_k8s_io_v1.ContainerScalingModeAuto
+	off := autoscaling_k8s_io_v1.ContainerScalingModeOff
+	return []autoscaling_k8s_io_v1.ContainerResourcePolicy{
+		{
+			ContainerName: source.GetName(),
+			Mode:          &auto,
+			MinAllowed:    minAllowed,
+			MaxAllowed:    maxAllowed,
+		},
+		{
+			ContainerName: autoscaling_k8s_io_v1.DefaultContainerResourcePolicy,
+			Mode:          &off,
+		},
+	}, nil
+}
+
+// Create renders a VerticalPodAutoscaler for the application's Deployment.
+// Recommendations are only computed, unless the application opts in to having them applied automatically.
+func Create(source resource.Source, ast *resource.Ast, options resource.Options, naisReplicas nais_io_v1.Replicas, naisAnnotations map[string]string) error {
+	mode, ok := naisAnnotations[ModeAnnotation]
+	if !options.VerticalPodAutoscalerEnabled {
+		if ok {
+			return fmt.Errorf("annotation '%s' is not supported in this cluster", ModeAnnotation)
+		}
+		return nil
+	}
+
+	updateMode := autoscaling_k8s_io_v1.UpdateModeOff
+	vpa := &autoscaling_k8s_io_v1.VerticalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "VerticalPodAutoscaler",
+			APIVersion: autoscaling_k8s_io_v1.GroupVersion.Identifier(),
+		},
+		ObjectMeta: resource.CreateObjectMeta(source),
+		Spec: autoscaling_k8s_io_v1.VerticalPodAutoscalerSpec{
+			TargetRef: &autoscalingv1.CrossVersionObjectReference{
+				APIVersion: "apps/v1",
+				Kind:       options.WorkloadKind(),
+				Name:       source.GetName(),
+			},
+			UpdatePolicy: &autoscaling_k8s_io_v1.PodUpdatePolicy{
+				UpdateMode: &updateMode,
+			},
+		},
+	}
+
+	switch mode {
+	case "", ModeOff:
+	case ModeAuto:
+		if !options.VerticalPodAutoscaler.AllowAuto {
+			return fmt.Errorf("vertical pod autoscaler: mode '%s' is not allowed in this cluster", ModeAuto)
+		}
+		// Both autoscalers would act on CPU usage, and work against each other.
+		if _, ok := naisAnnotations[keda.Annotation]; ok || naisReplicas.Min != naisReplicas.Max {
+			return fmt.Errorf("vertical pod autoscaler: mode '%s' cannot be combined with horizontal autoscaling; set replicas.min equal to replicas.max", ModeAuto)
+		}
+		policies, err := containerPolicies(source, options.VerticalPodAutoscaler)
+		if err != nil {
+			return fmt.Errorf("vertical pod autoscaler: %s", err)
+		}
+		updateMode = autoscaling_k8s_io_v1.UpdateModeAuto
+		vpa.Spec.ResourcePolicy = &autoscaling_k8s_io_v1.PodResourcePolicy{
+			ContainerPolicies: policies,
+		}
+	default:
+		return fmt.Errorf("vertical pod autoscaler: unsupported mode '%s'; use '%s' or '%s'", mode, ModeOff, ModeAuto)
+	}
+
+	ast.AppendOperation(resource.OperationCreateOrUpdate, vpa)
+	return nil
+}
+
+// Deviation is a resource request that is far from the recommendation.
+type Deviation struct {
+	Resource    corev1.ResourceName
+	Requested   k8sresource.Quantity
+	Recommended k8sresource.Quantity
+}
+
+func (d Deviation) String() string {
+	return fmt.Sprintf("%s requested %s, recommended %s", d.Resource, d.Requested.String(), d.Recommended.String())
+}
+
+// Requests returns the resource requests of an application, skipping values that cannot be parsed.
+func Requests(naisResources nais_io_v1.ResourceRequirements) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    naisResources.Requests.Cpu,
+		corev1.ResourceMemory: naisResources.Requests.Memory,
+	} {
+		quantity, err := k8sresource.ParseQuantity(value)
+		if err == nil {
+			requests[name] = quantity
+		}
+	}
+	return requests
+}
+
+// Target returns the recommended resource requests for a container,
+// or nil if the VerticalPodAutoscaler has no recommendation yet.
+func Target(vpa *autoscaling_k8s_io_v1.VerticalPodAutoscaler, containerName string) corev1.ResourceList {
+	if vpa.Status.Recommendation == nil {
+		return nil
+	}
+	for _, recommendation := range vpa.Status.Recommendation.ContainerRecommendations {
+		if recommendation.ContainerName == containerName {
+			return recommendation.Target
+		}
+	}
+	return nil
+}
+
+// Deviations compares resource requests with the recommended target for a container, and returns the requests
+// that are more than threshold times higher or lower than the recommendation. Nothing is returned until the
+// VerticalPodAutoscaler has a recommendation.
+func Deviations(vpa *autoscaling_k8s_io_v1.VerticalPodAutoscaler, containerName string, requests corev1.ResourceList, threshold float64) []Deviation {
+	if threshold <= 1 {
+		return nil
+	}
+
+	deviations := make([]Deviation, 0)
+	for name, target := range Target(vpa, containerName) {
+		requested, ok := requests[name]
+		if !ok || target.IsZero() {
+			continue
+		}
+		ratio := float64(requested.MilliValue()) / float64(target.MilliValue())
+		if ratio > threshold || ratio < 1/threshold {
+			deviations = append(deviations, Deviation{
+				Resource:    name,
+				Requested:   requested,
+				Recommended: target,
+			})
+		}
+	}
+
+	sort.Slice(deviations, func(i, j int) bool {
+		return deviations[i].Resource < deviations[j].Resource
+	})
+
+	return deviations
+}
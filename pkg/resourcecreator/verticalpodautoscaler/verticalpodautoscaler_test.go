@@ -0,0 +1,92 @@
+package verticalpodautoscaler_test
+
+import (
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	autoscaling_k8s_io_v1 "github.com/nais/naiserator/pkg/apis/autoscaling.k8s.io/v1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/resourcecreator/verticalpodautoscaler"
+	"github.com/nais/naiserator/pkg/test/fixtures"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+func recommendation(cpu, memory string) *autoscaling_k8s_io_v1.VerticalPodAutoscaler {
+	return &autoscaling_k8s_io_v1.VerticalPodAutoscaler{
+		Status: autoscaling_k8s_io_v1.VerticalPodAutoscalerStatus{
+			Recommendation: &autoscaling_k8s_io_v1.RecommendedPodResources{
+				ContainerRecommendations: []autoscaling_k8s_io_v1.RecommendedContainerResources{
+					{
+						ContainerName: "myapplication",
+						Target: corev1.ResourceList{
+							corev1.ResourceCPU:    k8sresource.MustParse(cpu),
+							corev1.ResourceMemory: k8sresource.MustParse(memory),
+						},
+					},
+					{
+						ContainerName: "sidecar",
+						Target: corev1.ResourceList{
+							corev1.ResourceCPU: k8sresource.MustParse("1m"),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestDeviations(t *testing.T) {
+	requests := verticalpodautoscaler.Requests(nais_io_v1.ResourceRequirements{
+		Requests: &nais_io_v1.ResourceSpec{Cpu: "200m", Memory: "256Mi"},
+	})
+
+	t.Run("no recommendation yet", func(t *testing.T) {
+		deviations := verticalpodautoscaler.Deviations(&autoscaling_k8s_io_v1.VerticalPodAutoscaler{}, "myapplication", requests, 2)
+		assert.Empty(t, deviations)
+	})
+
+	t.Run("requests within the threshold", func(t *testing.T) {
+		deviations := verticalpodautoscaler.Deviations(recommendation("150m", "400Mi"), "myapplication", requests, 2)
+		assert.Empty(t, deviations)
+	})
+
+	t.Run("requests far from the recommendation", func(t *testing.T) {
+		deviations := verticalpodautoscaler.Deviations(recommendation("25m", "1Gi"), "myapplication", requests, 2)
+		assert.Len(t, deviations, 2)
+		assert.Equal(t, "cpu requested 200m, recommended 25m", deviations[0].String())
+		assert.Equal(t, "memory requested 256Mi, recommended 1Gi", deviations[1].String())
+	})
+}
+
+func TestCreate(t *testing.T) {
+	app := fixtures.MinimalApplication()
+	err := app.ApplyDefaults()
+	assert.NoError(t, err)
+
+	t.Run("nothing is created when disabled", func(t *testing.T) {
+		ast := resource.NewAst()
+		err := verticalpodautoscaler.Create(app, ast, resource.Options{}, *app.Spec.Replicas, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, ast.Operations)
+	})
+
+	for _, test := range []struct {
+		name    string
+		options resource.Options
+		mode    string
+		err     string
+	}{
+		{"annotation when disabled", resource.Options{}, "off", "annotation 'nais.io/vertical-pod-autoscaler' is not supported in this cluster"},
+		{"unknown mode", resource.Options{VerticalPodAutoscalerEnabled: true}, "initial", "vertical pod autoscaler: unsupported mode 'initial'; use 'off' or 'auto'"},
+		{"auto mode not allowed", resource.Options{VerticalPodAutoscalerEnabled: true}, "auto", "vertical pod autoscaler: mode 'auto' is not allowed in this cluster"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			err := verticalpodautoscaler.Create(app, resource.NewAst(), test.options, *app.Spec.Replicas, map[string]string{
+				verticalpodautoscaler.ModeAnnotation: test.mode,
+			})
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
@@ -4,14 +4,25 @@ import (
 	iam_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/iam.cnrm.cloud.google.com/v1beta1"
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	sql_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/sql.cnrm.cloud.google.com/v1beta1"
-	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
+	autoscaling_k8s_io_v1 "github.com/nais/naiserator/pkg/apis/autoscaling.k8s.io/v1"
+	bigquery_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/bigquery.cnrm.cloud.google.com/v1beta1"
+	cert_manager_io_v1 "github.com/nais/naiserator/pkg/apis/cert-manager.io/v1"
+	cilium_io_v2 "github.com/nais/naiserator/pkg/apis/cilium.io/v2"
+	gateway_networking_k8s_io_v1alpha2 "github.com/nais/naiserator/pkg/apis/gateway.networking.k8s.io/v1alpha2"
+	keda_sh_v1alpha1 "github.com/nais/naiserator/pkg/apis/keda.sh/v1alpha1"
+	policy_linkerd_io_v1alpha1 "github.com/nais/naiserator/pkg/apis/policy.linkerd.io/v1alpha1"
+	policy_linkerd_io_v1beta1 "github.com/nais/naiserator/pkg/apis/policy.linkerd.io/v1beta1"
+	pubsub_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/pubsub.cnrm.cloud.google.com/v1beta1"
+	redis_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/redis.cnrm.cloud.google.com/v1beta1"
+	secrets_store_csi_x_k8s_io_v1alpha1 "github.com/nais/naiserator/pkg/apis/secrets-store.csi.x-k8s.io/v1alpha1"
+	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	networkingv1beta1 "k8s.io/api/networking/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // Maintain a list of resources that should be cleaned up during Application synchronization.
@@ -20,16 +31,16 @@ import (
 // These are usually the types we persist to the cluster with names different than the application name.
 
 // Resources that can be queried in all clusters
-func GenericListers() []runtime.Object {
-	return []runtime.Object{
+func GenericListers() []client.ObjectList {
+	return []client.ObjectList{
 		// Kubernetes internals
 		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
 		&v2beta2.HorizontalPodAutoscalerList{},
 		&corev1.SecretList{},
 		&corev1.ServiceAccountList{},
 		&corev1.ServiceList{},
 		&networkingv1.NetworkPolicyList{},
-		&networkingv1beta1.IngressList{},
 		&rbacv1.RoleBindingList{},
 		&rbacv1.RoleList{},
 
@@ -41,12 +52,31 @@ func GenericListers() []runtime.Object {
 	}
 }
 
+// Ingresses in the API version they are created with.
+// Both API versions serve the same objects, so only one of them can be listed.
+// Otherwise, an Ingress would be deleted through one API version after being updated through the other.
+func IngressListers(v1beta1 bool) []client.ObjectList {
+	if v1beta1 {
+		return []client.ObjectList{
+			&networkingv1beta1.IngressList{},
+		}
+	}
+	return []client.ObjectList{
+		&networkingv1.IngressList{},
+	}
+}
+
 // Resources that exist only in GCP clusters
-func GCPListers() []runtime.Object {
-	return []runtime.Object{
+func GCPListers() []client.ObjectList {
+	return []client.ObjectList{
+		// Datasets are not listed, as removing a dataset from the application spec would delete it along with its data.
+		&bigquery_cnrm_cloud_google_com_v1beta1.BigQueryTableList{},
 		&iam_cnrm_cloud_google_com_v1beta1.IAMPolicyList{},
 		&iam_cnrm_cloud_google_com_v1beta1.IAMPolicyMemberList{},
 		&iam_cnrm_cloud_google_com_v1beta1.IAMServiceAccountList{},
+		&pubsub_cnrm_cloud_google_com_v1beta1.PubSubSubscriptionList{},
+		&pubsub_cnrm_cloud_google_com_v1beta1.PubSubTopicList{},
+		&redis_cnrm_cloud_google_com_v1beta1.RedisInstanceList{},
 		&sql_cnrm_cloud_google_com_v1beta1.SQLDatabaseList{},
 		&sql_cnrm_cloud_google_com_v1beta1.SQLInstanceList{},
 		&sql_cnrm_cloud_google_com_v1beta1.SQLUserList{},
@@ -54,3 +84,55 @@ func GCPListers() []runtime.Object {
 		&storage_cnrm_cloud_google_com_v1beta1.StorageBucketList{},
 	}
 }
+
+// Resources that exist only in clusters with the CSI secret store driver
+func SecretManagerListers() []client.ObjectList {
+	return []client.ObjectList{
+		&secrets_store_csi_x_k8s_io_v1alpha1.SecretProviderClassList{},
+	}
+}
+
+// Resources that exist only in clusters with Gateway API enabled
+func GatewayAPIListers() []client.ObjectList {
+	return []client.ObjectList{
+		&gateway_networking_k8s_io_v1alpha2.HTTPRouteList{},
+	}
+}
+
+// Resources that exist only in clusters with cert-manager enabled
+func CertManagerListers() []client.ObjectList {
+	return []client.ObjectList{
+		&cert_manager_io_v1.CertificateList{},
+	}
+}
+
+// Resources that exist only in clusters with Cilium network policies enabled
+func CiliumListers() []client.ObjectList {
+	return []client.ObjectList{
+		&cilium_io_v2.CiliumNetworkPolicyList{},
+	}
+}
+
+// Resources created for KEDA autoscaling; listed even when KEDA is disabled, so that they can be cleaned up
+func KedaListers() []client.ObjectList {
+	return []client.ObjectList{
+		&keda_sh_v1alpha1.ScaledObjectList{},
+		&keda_sh_v1alpha1.TriggerAuthenticationList{},
+	}
+}
+
+// Resources that exist only in clusters with VerticalPodAutoscaler enabled
+func VerticalPodAutoscalerListers() []client.ObjectList {
+	return []client.ObjectList{
+		&autoscaling_k8s_io_v1.VerticalPodAutoscalerList{},
+	}
+}
+
+// Resources that exist only in clusters with Linkerd authorization policies enabled
+func LinkerdPolicyListers() []client.ObjectList {
+	return []client.ObjectList{
+		&policy_linkerd_io_v1alpha1.AuthorizationPolicyList{},
+		&policy_linkerd_io_v1alpha1.MeshTLSAuthenticationList{},
+		&policy_linkerd_io_v1beta1.ServerList{},
+	}
+}
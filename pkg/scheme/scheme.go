@@ -0,0 +1,54 @@
+package naiserator_scheme
+
+import (
+	aiven_nais_io_v1 "github.com/nais/liberator/pkg/apis/aiven.nais.io/v1"
+	iam_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/iam.cnrm.cloud.google.com/v1beta1"
+	kafka_nais_io_v1 "github.com/nais/liberator/pkg/apis/kafka.nais.io/v1"
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	sql_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/sql.cnrm.cloud.google.com/v1beta1"
+	liberator_scheme "github.com/nais/liberator/pkg/scheme"
+	autoscaling_k8s_io_v1 "github.com/nais/naiserator/pkg/apis/autoscaling.k8s.io/v1"
+	bigquery_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/bigquery.cnrm.cloud.google.com/v1beta1"
+	cert_manager_io_v1 "github.com/nais/naiserator/pkg/apis/cert-manager.io/v1"
+	cilium_io_v2 "github.com/nais/naiserator/pkg/apis/cilium.io/v2"
+	gateway_networking_k8s_io_v1alpha2 "github.com/nais/naiserator/pkg/apis/gateway.networking.k8s.io/v1alpha2"
+	keda_sh_v1alpha1 "github.com/nais/naiserator/pkg/apis/keda.sh/v1alpha1"
+	policy_linkerd_io_v1alpha1 "github.com/nais/naiserator/pkg/apis/policy.linkerd.io/v1alpha1"
+	policy_linkerd_io_v1beta1 "github.com/nais/naiserator/pkg/apis/policy.linkerd.io/v1beta1"
+	pubsub_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/pubsub.cnrm.cloud.google.com/v1beta1"
+	redis_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/redis.cnrm.cloud.google.com/v1beta1"
+	secrets_store_csi_x_k8s_io_v1alpha1 "github.com/nais/naiserator/pkg/apis/secrets-store.csi.x-k8s.io/v1alpha1"
+	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+)
+
+// All returns a scheme with all native Kubernetes types and the types supported by liberator,
+// plus third party types that are maintained in this repository.
+//
+// Groups maintained in this repository replace those of liberator, as a scheme cannot hold two
+// different Go types for the same kind.
+func All() (*runtime.Scheme, error) {
+	return liberator_scheme.Scheme(
+		nais_io_v1alpha1.AddToScheme,
+		nais_io_v1.AddToScheme,
+		iam_cnrm_cloud_google_com_v1beta1.AddToScheme,
+		sql_cnrm_cloud_google_com_v1beta1.AddToScheme,
+		bigquery_cnrm_cloud_google_com_v1beta1.AddToScheme,
+		storage_cnrm_cloud_google_com_v1beta1.AddToScheme,
+		pubsub_cnrm_cloud_google_com_v1beta1.AddToScheme,
+		secrets_store_csi_x_k8s_io_v1alpha1.AddToScheme,
+		redis_cnrm_cloud_google_com_v1beta1.AddToScheme,
+		gateway_networking_k8s_io_v1alpha2.AddToScheme,
+		cert_manager_io_v1.AddToScheme,
+		cilium_io_v2.AddToScheme,
+		policy_linkerd_io_v1alpha1.AddToScheme,
+		policy_linkerd_io_v1beta1.AddToScheme,
+		keda_sh_v1alpha1.AddToScheme,
+		autoscaling_k8s_io_v1.AddToScheme,
+		clientgoscheme.AddToScheme,
+		aiven_nais_io_v1.AddToScheme,
+		kafka_nais_io_v1.AddToScheme,
+	)
+}
@@ -0,0 +1,69 @@
+package synchronizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/nais/naiserator/pkg/policygraph"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Machine readable event "Reason" fields for access policy consistency reports.
+const (
+	EventAccessPolicyInconsistent = "AccessPolicyInconsistent"
+	EventAccessPolicyConsistent   = "AccessPolicyConsistent"
+)
+
+// checkAccessPolicy updates the access policy graph with an application or naisjob,
+// and reports its access policy issues as an event whenever they change.
+func (n *Synchronizer) checkAccessPolicy(ctx context.Context, source resource.Source, node *policygraph.Node) {
+	if n.AccessPolicyGraph == nil {
+		return
+	}
+
+	logger := log.WithFields(source.LogFields())
+	key := node.Key()
+
+	err := n.AccessPolicyGraph.Load(ctx, n.Client)
+	if err != nil {
+		logger.Errorf("Access policy report: %s", err)
+		return
+	}
+	n.AccessPolicyGraph.Set(node)
+
+	namespaces, err := policygraph.ExistingNamespaces(ctx, n.Client, node.Namespaces(n.Config.ClusterName))
+	if err != nil {
+		logger.Errorf("Access policy report: %s", err)
+		return
+	}
+
+	issues := n.AccessPolicyGraph.Issues(key, namespaces)
+	if !n.AccessPolicyGraph.Changed(key, issues) {
+		return
+	}
+
+	event := resource.CreateEvent(source, EventAccessPolicyConsistent, "Access policy rules match the access policies of other applications", "Normal")
+	if len(issues) > 0 {
+		messages := make([]string, len(issues))
+		for i, issue := range issues {
+			messages[i] = issue.Message
+		}
+		event = resource.CreateEvent(source, EventAccessPolicyInconsistent, fmt.Sprintf("Access policy is inconsistent: %s", strings.Join(messages, "; ")), "Warning")
+	}
+
+	_, err = n.reportEvent(ctx, event)
+	if err != nil {
+		logger.Errorf("Access policy report: unable to report event: %s", err)
+	}
+}
+
+// removeAccessPolicy removes a deleted application or naisjob from the access policy graph.
+func (n *Synchronizer) removeAccessPolicy(nodeType string, key client.ObjectKey) {
+	if n.AccessPolicyGraph == nil {
+		return
+	}
+	n.AccessPolicyGraph.Remove(policygraph.Key{Type: nodeType, Namespace: key.Namespace, Name: key.Name})
+}
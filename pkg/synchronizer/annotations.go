@@ -0,0 +1,54 @@
+package synchronizer
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"strings"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+)
+
+// Annotations with these prefixes configure the resources created for applications and naisjobs.
+var hashedAnnotationPrefixes = []string{
+	"nais.io/",
+	"nginx.ingress.kubernetes.io/",
+}
+
+// Annotations set by deployment tooling on every deploy, which do not change any resources.
+var unhashedAnnotations = map[string]bool{
+	nais_io_v1.DeploymentCorrelationIDAnnotation: true,
+	nais_io_v1.SkipDeploymentMessageAnnotation:   true,
+	nais_io_v1.LastSyncedHashAnnotation:          true,
+}
+
+func hashedAnnotation(key string) bool {
+	if unhashedAnnotations[key] {
+		return false
+	}
+	for _, prefix := range hashedAnnotationPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// annotationsHash extends the synchronization hash with the annotations that configure resources,
+// as the hash of the application or naisjob only covers its spec and labels.
+// Without them, changing an annotation on its own is not rolled out,
+// and an annotation that caused a permanent failure cannot be corrected.
+func annotationsHash(hash string, annotations map[string]string) string {
+	values := make([]string, 0)
+	for key, value := range annotations {
+		if hashedAnnotation(key) {
+			values = append(values, fmt.Sprintf("%s=%s", key, value))
+		}
+	}
+	if len(values) == 0 {
+		return hash
+	}
+	sort.Strings(values)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\nannotations\n%s", hash, strings.Join(values, "\n"))))
+	return fmt.Sprintf("%x", sum[:8])
+}
@@ -0,0 +1,79 @@
+package synchronizer_test
+
+import (
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	naiserator_scheme "github.com/nais/naiserator/pkg/scheme"
+	"github.com/nais/naiserator/pkg/synchronizer"
+	"github.com/nais/naiserator/pkg/test/fixtures"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestSynchronizer(t *testing.T) *synchronizer.Synchronizer {
+	scheme, err := naiserator_scheme.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &synchronizer.Synchronizer{
+		Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		Scheme: scheme,
+	}
+}
+
+func TestPrepareAnnotationChanges(t *testing.T) {
+	n := newTestSynchronizer(t)
+
+	t.Run("application", func(t *testing.T) {
+		app := fixtures.MinimalApplication()
+		rollout, err := n.Prepare(app.DeepCopy())
+		assert.NoError(t, err)
+		if !assert.NotNil(t, rollout) {
+			return
+		}
+		app.Status.SynchronizationHash = rollout.SynchronizationHash
+
+		app.Annotations = map[string]string{nais_io_v1.DeploymentCorrelationIDAnnotation: "deploy-id"}
+		rollout, err = n.Prepare(app.DeepCopy())
+		assert.NoError(t, err)
+		assert.Nil(t, rollout, "deployment correlation ID does not change resources")
+
+		app.Annotations["nais.io/topology-spread"] = `{"zone": "ScheduleAnyway"}`
+		rollout, err = n.Prepare(app.DeepCopy())
+		assert.NoError(t, err)
+		if assert.NotNil(t, rollout, "annotation change is synchronized") {
+			assert.NotEqual(t, app.Status.SynchronizationHash, rollout.SynchronizationHash)
+		}
+	})
+
+	t.Run("naisjob", func(t *testing.T) {
+		job := &nais_io_v1.Naisjob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "myjob",
+				Namespace: "mynamespace",
+				UID:       "123456",
+				Labels: map[string]string{
+					"team": "myteam",
+				},
+			},
+			Spec: nais_io_v1.NaisjobSpec{
+				Image: "image",
+			},
+		}
+		rollout, err := n.PrepareNaisjob(job.DeepCopy())
+		assert.NoError(t, err)
+		if !assert.NotNil(t, rollout) {
+			return
+		}
+		job.Status.SynchronizationHash = rollout.SynchronizationHash
+
+		job.Annotations = map[string]string{"nais.io/topology-spread": `{"zone": "ScheduleAnyway"}`}
+		rollout, err = n.PrepareNaisjob(job.DeepCopy())
+		assert.NoError(t, err)
+		if assert.NotNil(t, rollout, "annotation change is synchronized") {
+			assert.NotEqual(t, job.Status.SynchronizationHash, rollout.SynchronizationHash)
+		}
+	})
+}
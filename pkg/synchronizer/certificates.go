@@ -0,0 +1,148 @@
+package synchronizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	cert_manager_io_v1 "github.com/nais/naiserator/pkg/apis/cert-manager.io/v1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Machine readable event "Reason" fields for certificates issued to custom ingress domains.
+const (
+	EventCertificateReady    = "CertificateReady"
+	EventCertificateNotReady = "CertificateNotReady"
+)
+
+// CertificatesStatusField is the status field reporting certificates issued for custom ingress domains.
+const CertificatesStatusField = "certificates"
+
+// CertificateStatus reports whether cert-manager has issued a certificate for custom ingress domains.
+type CertificateStatus struct {
+	Name   string `json:"name"`
+	Ready  bool   `json:"ready"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// CertificateReady returns true if cert-manager has issued the certificate.
+// Otherwise, the reason reported by cert-manager is returned.
+func CertificateReady(cert *cert_manager_io_v1.Certificate) (bool, string) {
+	for _, condition := range cert.Status.Conditions {
+		if condition.Type != cert_manager_io_v1.CertificateConditionReady {
+			continue
+		}
+		if condition.Status == metav1.ConditionTrue {
+			return true, ""
+		}
+		return false, condition.Message
+	}
+	return false, "certificate has not been processed by cert-manager"
+}
+
+// certificatesStatus returns the status of all cert-manager Certificates belonging to an application.
+func (n *Synchronizer) certificatesStatus(ctx context.Context, app *nais_io_v1alpha1.Application) ([]CertificateStatus, error) {
+	certs := &cert_manager_io_v1.CertificateList{}
+	err := n.List(ctx, certs, client.InNamespace(app.GetNamespace()), client.MatchingLabels{"app": app.GetName()})
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]CertificateStatus, 0, len(certs.Items))
+	for i := range certs.Items {
+		ready, reason := CertificateReady(&certs.Items[i])
+		statuses = append(statuses, CertificateStatus{
+			Name:   certs.Items[i].GetName(),
+			Ready:  ready,
+			Reason: reason,
+		})
+	}
+
+	return statuses, nil
+}
+
+// monitorCertificatesRoutine waits for cert-manager to issue certificates for custom ingress domains.
+// Certificate readiness is stored in the application status whenever it changes, and the outcome is reported as an event.
+// Certificates that are not ready within the rollout timeout are reported with the reason given by cert-manager.
+func (n *Synchronizer) monitorCertificatesRoutine(ctx context.Context, app *nais_io_v1alpha1.Application, logger log.Entry) {
+	if !n.ResourceOptions.CertManagerEnabled {
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, n.Config.Synchronizer.RolloutTimeout)
+	defer cancel()
+
+	var statuses []CertificateStatus
+
+	for {
+		select {
+		case <-time.After(n.Config.Synchronizer.RolloutCheckInterval):
+			var err error
+			statuses, err = n.certificatesStatus(timeoutCtx, app)
+			if err != nil {
+				logger.Errorf("Monitor certificates: failed to query certificates: %s", err)
+				continue
+			}
+
+			// Custom ingress domains have been removed from the application.
+			if len(statuses) == 0 {
+				_, err = n.updateStatusAnnotation(timeoutCtx, app, CertificatesStatusField, nil)
+				if err != nil {
+					logger.Errorf("Monitor certificates: store certificate status: %s", err)
+				}
+				return
+			}
+
+			changed, err := n.updateStatusAnnotation(timeoutCtx, app, CertificatesStatusField, statuses)
+			if err != nil {
+				logger.Errorf("Monitor certificates: store certificate status: %s", err)
+				continue
+			}
+
+			if !certificatesReady(statuses) {
+				continue
+			}
+
+			logger.Debugf("Monitor certificates: all certificates are ready")
+			if changed {
+				_, err = n.reportEvent(ctx, resource.CreateEvent(app, EventCertificateReady, "Certificates for custom ingress domains have been issued", "Normal"))
+				if err != nil {
+					logger.Errorf("Monitor certificates: unable to report certificate ready event: %s", err)
+				}
+			}
+			return
+
+		case <-timeoutCtx.Done():
+			if ctx.Err() != nil {
+				logger.Debugf("Monitor certificates: application has been redeployed; cancelling monitoring")
+				return
+			}
+			reasons := make([]string, 0, len(statuses))
+			for _, status := range statuses {
+				if !status.Ready {
+					reasons = append(reasons, fmt.Sprintf("%s: %s", status.Name, status.Reason))
+				}
+			}
+			message := fmt.Sprintf("Certificates for custom ingress domains are not ready: %s", strings.Join(reasons, "; "))
+			_, err := n.reportEvent(ctx, resource.CreateEvent(app, EventCertificateNotReady, message, "Warning"))
+			if err != nil {
+				logger.Errorf("Monitor certificates: unable to report certificate not ready event: %s", err)
+			}
+			return
+		}
+	}
+}
+
+func certificatesReady(statuses []CertificateStatus) bool {
+	for _, status := range statuses {
+		if !status.Ready {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,34 @@
+package synchronizer_test
+
+import (
+	"testing"
+
+	cert_manager_io_v1 "github.com/nais/naiserator/pkg/apis/cert-manager.io/v1"
+	"github.com/nais/naiserator/pkg/synchronizer"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertificateReady(t *testing.T) {
+	cert := &cert_manager_io_v1.Certificate{}
+
+	ready, reason := synchronizer.CertificateReady(cert)
+	assert.False(t, ready)
+	assert.NotEmpty(t, reason)
+
+	cert.Status.Conditions = []cert_manager_io_v1.CertificateCondition{
+		{
+			Type:    cert_manager_io_v1.CertificateConditionReady,
+			Status:  metav1.ConditionFalse,
+			Message: "Issuing certificate as Secret does not exist",
+		},
+	}
+	ready, reason = synchronizer.CertificateReady(cert)
+	assert.False(t, ready)
+	assert.Equal(t, "Issuing certificate as Secret does not exist", reason)
+
+	cert.Status.Conditions[0].Status = metav1.ConditionTrue
+	ready, reason = synchronizer.CertificateReady(cert)
+	assert.True(t, ready)
+	assert.Empty(t, reason)
+}
@@ -0,0 +1,57 @@
+package synchronizer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	gateway_networking_k8s_io_v1alpha2 "github.com/nais/naiserator/pkg/apis/gateway.networking.k8s.io/v1alpha2"
+	"github.com/nais/naiserator/pkg/resourcecreator/ingress"
+	naiserator_scheme "github.com/nais/naiserator/pkg/scheme"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// checkIngressCollisions makes sure that no other application in the cluster serves the same host and path combinations
+// as this rollout. nginx will silently pick one of them, so we fail early instead.
+func (n *Synchronizer) checkIngressCollisions(ctx context.Context, rollout Rollout) error {
+	desired := make([]runtime.Object, 0)
+	for _, rop := range rollout.ResourceOperations {
+		if len(ingress.Routes(rop.Resource)) > 0 {
+			desired = append(desired, rop.Resource)
+		}
+	}
+
+	if len(desired) == 0 {
+		return nil
+	}
+
+	lists := naiserator_scheme.IngressListers(rollout.ResourceOptions.IngressV1beta1)
+	if rollout.ResourceOptions.GatewayAPI {
+		lists = append(lists, &gateway_networking_k8s_io_v1alpha2.HTTPRouteList{})
+	}
+
+	existing := make([]runtime.Object, 0)
+	for _, list := range lists {
+		err := n.List(ctx, list)
+		if err != nil {
+			return fmt.Errorf("list %T: %w", list, err)
+		}
+		_ = meta.EachListItem(list, func(item runtime.Object) error {
+			existing = append(existing, item)
+			return nil
+		})
+	}
+
+	collisions := ingress.Collisions(rollout.Source, desired, existing)
+	if len(collisions) == 0 {
+		return nil
+	}
+
+	messages := make([]string, len(collisions))
+	for i := range collisions {
+		messages[i] = collisions[i].Error()
+	}
+
+	return fmt.Errorf("ingress collision: %s", strings.Join(messages, "; "))
+}
@@ -0,0 +1,196 @@
+package synchronizer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/networkpolicy"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// External host names are resolved again at this interval, so that network policies follow DNS changes.
+	externalHostRefreshInterval = 5 * time.Minute
+
+	EventStrictEgress = "StrictEgress"
+)
+
+// LookupHost resolves external host names when restricting egress with IP blocks.
+// Replaceable for testing.
+var LookupHost = func(ctx context.Context, host string) ([]string, error) {
+	return net.DefaultResolver.LookupHost(ctx, host)
+}
+
+// strictEgressEnabled decides whether strict egress applies to a namespace.
+// The feature flag is a kill switch; namespace annotations only override the cluster default while it is enabled.
+func strictEgressEnabled(cfg config.Config, namespace *corev1.Namespace) bool {
+	if !cfg.Features.StrictEgress {
+		return false
+	}
+	switch namespace.Annotations[networkpolicy.StrictEgressAnnotation] {
+	case "enabled":
+		return true
+	case "disabled":
+		return false
+	default:
+		return cfg.StrictEgress.Default
+	}
+}
+
+// strictEgress enables strict egress mode according to cluster configuration and namespace annotations,
+// and resolves the addresses of external hosts if the egress provider needs them.
+func (n *Synchronizer) strictEgress(ctx context.Context, options *resource.Options, source resource.Source, naisAccessPolicy *nais_io_v1.AccessPolicy) error {
+	options.StrictEgressEnabled = false
+	if !n.Config.Features.StrictEgress {
+		return nil
+	}
+
+	namespace := &corev1.Namespace{}
+	err := n.Get(ctx, client.ObjectKey{Name: source.GetNamespace()}, namespace)
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("query existing namespace: %s", err)
+	}
+
+	options.StrictEgressEnabled = strictEgressEnabled(n.Config, namespace)
+	if !options.StrictEgressEnabled || !options.NetworkPolicy || naisAccessPolicy == nil {
+		return nil
+	}
+	if options.StrictEgress.Provider != config.StrictEgressProviderIPBlock {
+		return nil
+	}
+
+	options.ExternalHostAddresses = make(map[string][]string)
+	var lastKnown map[string][]string
+	for _, rule := range networkpolicy.ExternalHosts(*options, *naisAccessPolicy) {
+		if networkpolicy.IsAddress(rule.Host) || networkpolicy.IsWildcardHost(rule.Host) {
+			continue
+		}
+		addresses, err := LookupHost(ctx, rule.Host)
+		if err != nil {
+			// A failed lookup does not stop synchronization; the addresses allowed by the existing network policy are kept.
+			if lastKnown == nil {
+				lastKnown = n.lastKnownExternalHostAddresses(ctx, source)
+			}
+			addresses = lastKnown[rule.Host]
+			log.Warnf("Resolve external host '%s' for %s/%s: %s; keeping %d last known addresses", rule.Host, source.GetNamespace(), source.GetName(), err, len(addresses))
+		}
+		sort.Strings(addresses)
+		options.ExternalHostAddresses[rule.Host] = addresses
+	}
+
+	return nil
+}
+
+// lastKnownExternalHostAddresses reads the resolved addresses of external hosts from the existing network policy.
+func (n *Synchronizer) lastKnownExternalHostAddresses(ctx context.Context, source resource.Source) map[string][]string {
+	existing := &networkingv1.NetworkPolicy{}
+	err := n.Get(ctx, client.ObjectKey{Namespace: source.GetNamespace(), Name: source.GetName()}, existing)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Errorf("Query existing network policy for %s/%s: %s", source.GetNamespace(), source.GetName(), err)
+		}
+		return map[string][]string{}
+	}
+	return networkpolicy.ParseExternalHosts(existing.GetAnnotations()[networkpolicy.StrictEgressHostsAnnotation])
+}
+
+// unresolvedExternalHosts returns the external host names that are not allowed by address, because they could not be resolved.
+func unresolvedExternalHosts(options resource.Options, hosts []nais_io_v1.AccessPolicyExternalRule) []string {
+	unresolved := make([]string, 0)
+	if options.StrictEgress.Provider != config.StrictEgressProviderIPBlock {
+		return unresolved
+	}
+	for _, rule := range hosts {
+		if networkpolicy.IsAddress(rule.Host) || networkpolicy.IsWildcardHost(rule.Host) {
+			continue
+		}
+		if len(options.ExternalHostAddresses[rule.Host]) == 0 {
+			unresolved = append(unresolved, rule.Host)
+		}
+	}
+	sort.Strings(unresolved)
+	return unresolved
+}
+
+// strictEgressHash extends the synchronization hash with strict egress mode and the resolved addresses of external hosts,
+// so that network policies are updated when a namespace opts in or out, or when DNS records change.
+func strictEgressHash(hash string, options resource.Options) string {
+	if !options.StrictEgressEnabled {
+		return hash
+	}
+	hosts := make([]string, 0, len(options.ExternalHostAddresses))
+	for host, addresses := range options.ExternalHostAddresses {
+		hosts = append(hosts, fmt.Sprintf("%s=%s", host, strings.Join(addresses, ",")))
+	}
+	sort.Strings(hosts)
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\nstrict-egress\n%s", hash, strings.Join(hosts, "\n"))))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// strictEgressRequeue schedules another reconciliation of a workload whose external hosts are allowed by address,
+// so that their host names are resolved again.
+func (n *Synchronizer) strictEgressRequeue(naisAccessPolicy *nais_io_v1.AccessPolicy) ctrl.Result {
+	if !n.Config.Features.StrictEgress || n.Config.StrictEgress.Provider != config.StrictEgressProviderIPBlock {
+		return ctrl.Result{}
+	}
+	if naisAccessPolicy == nil || naisAccessPolicy.Outbound == nil || len(naisAccessPolicy.Outbound.External) == 0 {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: externalHostRefreshInterval}
+}
+
+// reportStrictEgress tells the workload owner which external destinations are allowed.
+// Connections to any other destination are dropped by the CNI.
+func (n *Synchronizer) reportStrictEgress(ctx context.Context, source resource.Source, naisAccessPolicy *nais_io_v1.AccessPolicy, options resource.Options, logger log.Entry) {
+	if !options.StrictEgressEnabled || !options.NetworkPolicy {
+		return
+	}
+
+	accessPolicy := nais_io_v1.AccessPolicy{}
+	if naisAccessPolicy != nil {
+		accessPolicy = *naisAccessPolicy
+	}
+	hosts := networkpolicy.ExternalHosts(options, accessPolicy)
+	allowed := networkpolicy.DescribeExternalHosts(options, hosts)
+
+	message := "Strict egress: connections to external hosts not declared in the access policy are dropped"
+	if len(allowed) > 0 {
+		message = fmt.Sprintf("%s; allowed: %s", message, strings.Join(allowed, "; "))
+	}
+	if options.StrictEgress.Provider == config.StrictEgressProviderCilium {
+		message = fmt.Sprintf("%s; inspect dropped connections with `hubble observe --namespace %s --verdict DROPPED`", message, source.GetNamespace())
+	}
+
+	logger.WithFields(log.Fields{
+		"strict_egress_provider": options.StrictEgress.Provider,
+		"strict_egress_allowed":  strings.Join(allowed, ";"),
+	}).Infof("Strict egress enabled")
+
+	_, err := n.reportEvent(ctx, resource.CreateEvent(source, EventStrictEgress, message, "Normal"))
+	if err != nil {
+		logger.Errorf("Unable to report strict egress event: %s", err)
+	}
+
+	unresolved := unresolvedExternalHosts(options, hosts)
+	if len(unresolved) == 0 {
+		return
+	}
+	message = fmt.Sprintf("Strict egress: external hosts could not be resolved, and connections to them are dropped until they resolve: %s", strings.Join(unresolved, ", "))
+	_, err = n.reportEvent(ctx, resource.CreateEvent(source, EventStrictEgress, message, "Warning"))
+	if err != nil {
+		logger.Errorf("Unable to report strict egress event: %s", err)
+	}
+}
@@ -0,0 +1,72 @@
+package synchronizer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
+	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/resourcecreator/networkpolicy"
+	"github.com/nais/naiserator/pkg/synchronizer"
+	"github.com/nais/naiserator/pkg/test/fixtures"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrepareStrictEgressLookupFailure(t *testing.T) {
+	lookupHost := synchronizer.LookupHost
+	defer func() {
+		synchronizer.LookupHost = lookupHost
+	}()
+	synchronizer.LookupHost = func(ctx context.Context, host string) ([]string, error) {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+
+	strictEgress := config.StrictEgress{
+		Provider: config.StrictEgressProviderIPBlock,
+		Default:  true,
+	}
+
+	n := newTestSynchronizer(t)
+	n.Config.Features.StrictEgress = true
+	n.Config.StrictEgress = strictEgress
+	n.ResourceOptions.NetworkPolicy = true
+	n.ResourceOptions.StrictEgress = strictEgress
+
+	app := fixtures.MinimalApplication()
+	app.Spec.AccessPolicy.Outbound = &nais_io_v1.AccessPolicyOutbound{
+		External: []nais_io_v1.AccessPolicyExternalRule{
+			{Host: "example.com"},
+		},
+	}
+
+	t.Run("without an existing network policy", func(t *testing.T) {
+		rollout, err := n.Prepare(app.DeepCopy())
+		assert.NoError(t, err)
+		if assert.NotNil(t, rollout) {
+			assert.Empty(t, rollout.ResourceOptions.ExternalHostAddresses["example.com"])
+		}
+	})
+
+	t.Run("last known addresses are kept", func(t *testing.T) {
+		existing := &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      app.GetName(),
+				Namespace: app.GetNamespace(),
+				Annotations: map[string]string{
+					networkpolicy.StrictEgressHostsAnnotation: "example.com=192.0.2.10,192.0.2.11",
+				},
+			},
+		}
+		err := n.Create(context.Background(), existing)
+		assert.NoError(t, err)
+
+		rollout, err := n.Prepare(app.DeepCopy())
+		assert.NoError(t, err)
+		if assert.NotNil(t, rollout) {
+			assert.Equal(t, []string{"192.0.2.10", "192.0.2.11"}, rollout.ResourceOptions.ExternalHostAddresses["example.com"])
+		}
+	})
+}
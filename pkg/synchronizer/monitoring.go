@@ -39,6 +39,8 @@ func (n *Synchronizer) produceDeploymentEvent(event *deployment.Event) (int64, e
 	return n.Kafka.Produce(payload)
 }
 
+// MonitorRollout watches the workload and certificates of an application until they are ready.
+// Monitoring is restarted whenever the application is rolled out again.
 func (n *Synchronizer) MonitorRollout(app *nais_io_v1alpha1.Application, logger log.Entry) {
 	objectKey := client.ObjectKey{
 		Name:      app.GetName(),
@@ -59,7 +61,17 @@ func (n *Synchronizer) MonitorRollout(app *nais_io_v1alpha1.Application, logger
 	rolloutMonitorLock.Unlock()
 
 	go func() {
-		n.monitorRolloutRoutine(ctx, app, logger)
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			n.monitorRolloutRoutine(ctx, app, logger)
+			wg.Done()
+		}()
+		go func() {
+			n.monitorCertificatesRoutine(ctx, app, logger)
+			wg.Done()
+		}()
+		wg.Wait()
 		cancel()
 		n.cancelMonitor(objectKey, &id)
 	}()
@@ -104,17 +116,16 @@ func (n *Synchronizer) monitorRolloutRoutine(ctx context.Context, app *nais_io_v
 	for {
 		select {
 		case <-time.After(n.Config.Synchronizer.RolloutCheckInterval):
-			deploy := &appsv1.Deployment{}
-			err := n.Get(ctx, objectKey, deploy)
+			complete, err := n.workloadComplete(ctx, app, objectKey)
 
 			if err != nil {
 				if !errors.IsNotFound(err) {
-					logger.Errorf("Monitor rollout: failed to query Deployment: %s", err)
+					logger.Errorf("Monitor rollout: failed to query workload: %s", err)
 				}
 				continue
 			}
 
-			if !deploymentComplete(deploy, &deploy.Status) {
+			if !complete {
 				continue
 			}
 
@@ -179,6 +190,25 @@ func (n *Synchronizer) monitorRolloutRoutine(ctx context.Context, app *nais_io_v
 	}
 }
 
+// workloadComplete checks whether the Deployment or StatefulSet of an application has rolled out completely.
+func (n *Synchronizer) workloadComplete(ctx context.Context, app *nais_io_v1alpha1.Application, objectKey client.ObjectKey) (bool, error) {
+	if isStatefulSet(app, n.ResourceOptions) {
+		statefulSet := &appsv1.StatefulSet{}
+		err := n.Get(ctx, objectKey, statefulSet)
+		if err != nil {
+			return false, err
+		}
+		return statefulSetComplete(statefulSet), nil
+	}
+
+	deploy := &appsv1.Deployment{}
+	err := n.Get(ctx, objectKey, deploy)
+	if err != nil {
+		return false, err
+	}
+	return deploymentComplete(deploy, &deploy.Status), nil
+}
+
 // deploymentComplete considers a deployment to be complete once all of its desired replicas
 // are updated and available, and no old pods are running.
 //
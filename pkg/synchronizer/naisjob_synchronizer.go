@@ -8,7 +8,10 @@ import (
 
 	nais_io_v1 "github.com/nais/liberator/pkg/apis/nais.io/v1"
 	"github.com/nais/naiserator/pkg/metrics"
+	"github.com/nais/naiserator/pkg/policygraph"
 	"github.com/nais/naiserator/pkg/resourcecreator"
+	"github.com/nais/naiserator/pkg/resourcecreator/google"
+	"github.com/nais/naiserator/pkg/resourcecreator/google/gcp"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -31,6 +34,7 @@ func (n *Synchronizer) ReconcileNaisjob(req ctrl.Request) (ctrl.Result, error) {
 				"naisjob":   req.Name,
 			})
 			logger.Infof("Naisjob has been deleted from Kubernetes")
+			n.removeAccessPolicy(policygraph.TypeNaisjob, req.NamespacedName)
 
 			err = nil
 		}
@@ -57,6 +61,8 @@ func (n *Synchronizer) ReconcileNaisjob(req ctrl.Request) (ctrl.Result, error) {
 		}
 	}()
 
+	n.checkAccessPolicy(ctx, naisjob, policygraph.NodeFromNaisjob(naisjob))
+
 	rollout, err := n.PrepareNaisjob(naisjob)
 	if err != nil {
 		naisjob.Status.SynchronizationState = EventFailedPrepare
@@ -67,7 +73,7 @@ func (n *Synchronizer) ReconcileNaisjob(req ctrl.Request) (ctrl.Result, error) {
 	if rollout == nil {
 		changed = false
 		logger.Debugf("Naisjob synchronization hash not changed; skipping synchronization")
-		return ctrl.Result{}, nil
+		return n.strictEgressRequeue(naisjob.Spec.AccessPolicy), nil
 	}
 
 	logger = *log.WithFields(naisjob.LogFields())
@@ -104,7 +110,9 @@ func (n *Synchronizer) ReconcileNaisjob(req ctrl.Request) (ctrl.Result, error) {
 		log.Errorf("While creating an event for this rollout, an error occurred: %s", err)
 	}
 
-	return ctrl.Result{}, nil
+	n.reportStrictEgress(ctx, naisjob, naisjob.Spec.AccessPolicy, rollout.ResourceOptions, logger)
+
+	return n.strictEgressRequeue(naisjob.Spec.AccessPolicy), nil
 }
 
 // PrepareNaisjob converts a NAIS Naisjob spec into a Rollout object.
@@ -127,6 +135,13 @@ func (n *Synchronizer) PrepareNaisjob(naisjob *nais_io_v1.Naisjob) (*Rollout, er
 	if err != nil {
 		return nil, fmt.Errorf("BUG: create naisjob hash: %s", err)
 	}
+	rollout.SynchronizationHash = annotationsHash(rollout.SynchronizationHash, naisjob.GetAnnotations())
+
+	err = n.strictEgress(ctx, &rollout.ResourceOptions, naisjob, naisjob.Spec.AccessPolicy)
+	if err != nil {
+		return nil, err
+	}
+	rollout.SynchronizationHash = strictEgressHash(rollout.SynchronizationHash, rollout.ResourceOptions)
 
 	// Skip processing if naisjob didn't change since last synchronization.
 	if naisjob.Status.SynchronizationHash == rollout.SynchronizationHash {
@@ -147,7 +162,7 @@ func (n *Synchronizer) PrepareNaisjob(naisjob *nais_io_v1.Naisjob) (*Rollout, er
 		return nil, fmt.Errorf("query existing namespace: %s", err)
 	}
 
-	if naisjob.Spec.GCP != nil {
+	if gcp.RequiresTeamProject(naisjob, naisjob.Spec.GCP) {
 		// App requests gcp resources, verify we've got a GCP team project ID
 		projectID, ok := namespace.Annotations["cnrm.cloud.google.com/project-id"]
 		if !ok {
@@ -155,6 +170,7 @@ func (n *Synchronizer) PrepareNaisjob(naisjob *nais_io_v1.Naisjob) (*Rollout, er
 			return nil, fmt.Errorf("GCP resources requested, but no team project ID annotation set on namespace %s (not running on GCP?)", naisjob.GetNamespace())
 		}
 		rollout.ResourceOptions.GoogleTeamProjectId = projectID
+		rollout.ResourceOptions.GoogleTeamProjectNumber = namespace.Annotations[google.ProjectNumberAnnotation]
 	}
 
 	// Create Linkerd resources only if feature is enabled and namespace is Linkerd-enabled
@@ -0,0 +1,60 @@
+package synchronizer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	google_redis_crd "github.com/nais/naiserator/pkg/apis/redis.cnrm.cloud.google.com/v1beta1"
+	"github.com/nais/naiserator/pkg/resourcecreator/google/redis"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	redisRetryInterval = time.Minute
+)
+
+// redisAddress looks up the address of the application's Redis instance, as reported by Config Connector.
+// An empty host is returned if the instance does not exist yet, or has not been provisioned.
+func (n *Synchronizer) redisAddress(ctx context.Context, app *nais_io_v1alpha1.Application) (string, int, error) {
+	if !google_redis.Requested(app) || len(n.ResourceOptions.GoogleProjectId) == 0 {
+		return "", 0, nil
+	}
+
+	instance := &google_redis_crd.RedisInstance{}
+	err := n.Get(ctx, client.ObjectKey{Name: google_redis.InstanceName(app), Namespace: app.GetNamespace()}, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return "", 0, nil
+		}
+		return "", 0, fmt.Errorf("query existing redis instance: %s", err)
+	}
+
+	return instance.Status.Host, instance.Status.Port, nil
+}
+
+// redisHash extends the synchronization hash with the address of the Redis instance, so that the application
+// is synchronized again when the address becomes known.
+func redisHash(hash, host string, port int) string {
+	if len(host) == 0 {
+		return hash
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s:%d", hash, host, port)))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// redisRequeue schedules another reconciliation of an application while its Redis instance is being provisioned.
+func (n *Synchronizer) redisRequeue(ctx context.Context, app *nais_io_v1alpha1.Application) ctrl.Result {
+	if !google_redis.Requested(app) || len(n.ResourceOptions.GoogleProjectId) == 0 {
+		return ctrl.Result{}
+	}
+	host, _, err := n.redisAddress(ctx, app)
+	if err == nil && len(host) > 0 {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: redisRetryInterval}
+}
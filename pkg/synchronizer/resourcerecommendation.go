@@ -0,0 +1,197 @@
+package synchronizer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	autoscaling_k8s_io_v1 "github.com/nais/naiserator/pkg/apis/autoscaling.k8s.io/v1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/resourcecreator/verticalpodautoscaler"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Machine readable event "Reason" fields for resource recommendations from VerticalPodAutoscalers.
+const (
+	EventResourceRequestsDeviate     = "ResourceRequestsDeviate"
+	EventResourceRequestsRecommended = "ResourceRequestsRecommended"
+)
+
+// ResourceRecommendationsStatusField is the status field reporting the resource recommendations of the VerticalPodAutoscaler.
+const ResourceRecommendationsStatusField = "resource-recommendations"
+
+// ResourceRecommendationStatus compares a resource request with the recommendation for the application container.
+type ResourceRecommendationStatus struct {
+	Resource    string `json:"resource"`
+	Requested   string `json:"requested,omitempty"`
+	Recommended string `json:"recommended"`
+	Deviates    bool   `json:"deviates"`
+}
+
+// ResourceRecommendationsStatus lists the recommended resource requests, sorted by resource name.
+// Returns nil until the VerticalPodAutoscaler has a recommendation.
+func ResourceRecommendationsStatus(target, requests corev1.ResourceList, deviations []verticalpodautoscaler.Deviation) []ResourceRecommendationStatus {
+	if len(target) == 0 {
+		return nil
+	}
+
+	deviates := make(map[corev1.ResourceName]bool)
+	for _, deviation := range deviations {
+		deviates[deviation.Resource] = true
+	}
+
+	statuses := make([]ResourceRecommendationStatus, 0, len(target))
+	for name, recommended := range target {
+		status := ResourceRecommendationStatus{
+			Resource:    string(name),
+			Recommended: recommended.String(),
+			Deviates:    deviates[name],
+		}
+		if requested, ok := requests[name]; ok {
+			status.Requested = requested.String()
+		}
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Resource < statuses[j].Resource
+	})
+
+	return statuses
+}
+
+// ResourceRecommendations remembers which deviations from resource recommendations have been reported for each
+// application. Applications are reconciled periodically, and the same deviation should only be reported once.
+type ResourceRecommendations struct {
+	lock     sync.Mutex
+	reported map[client.ObjectKey]string
+}
+
+func NewResourceRecommendations() *ResourceRecommendations {
+	return &ResourceRecommendations{
+		reported: make(map[client.ObjectKey]string),
+	}
+}
+
+// changed records the deviations of an application, and returns true if they differ from the last ones recorded.
+// Applications that have not been recorded count as having no deviations.
+func (r *ResourceRecommendations) changed(key client.ObjectKey, deviations []verticalpodautoscaler.Deviation) bool {
+	parts := make([]string, len(deviations))
+	for i, deviation := range deviations {
+		direction := "under"
+		if deviation.Requested.Cmp(deviation.Recommended) > 0 {
+			direction = "over"
+		}
+		parts[i] = fmt.Sprintf("%s:%s", deviation.Resource, direction)
+	}
+	fingerprint := strings.Join(parts, ",")
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.reported[key] == fingerprint {
+		return false
+	}
+	if len(fingerprint) == 0 {
+		delete(r.reported, key)
+	} else {
+		r.reported[key] = fingerprint
+	}
+	return true
+}
+
+func (r *ResourceRecommendations) remove(key client.ObjectKey) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.reported, key)
+}
+
+// checkResourceRecommendation records the recommendation of the VerticalPodAutoscaler in the application status,
+// and reports an event whenever the resource requests start or stop deviating from it.
+// The status is only changed in memory; returns true if it must be persisted.
+// Application defaults must be applied first.
+func (n *Synchronizer) checkResourceRecommendation(ctx context.Context, app *nais_io_v1alpha1.Application) bool {
+	logger := log.WithFields(app.LogFields())
+
+	statuses, err := n.resourceRecommendation(ctx, app, *logger)
+	if err != nil {
+		logger.Errorf("Resource recommendation: %s", err)
+		return false
+	}
+
+	// A nil slice must not be stored as a JSON null.
+	var value interface{}
+	if statuses != nil {
+		value = statuses
+	}
+	changed, err := setStatusAnnotation(app, ResourceRecommendationsStatusField, value)
+	if err != nil {
+		logger.Errorf("Resource recommendation: %s", err)
+		return false
+	}
+
+	return changed
+}
+
+// resourceRecommendation queries the VerticalPodAutoscaler of an application and reports deviating resource requests.
+// Returns the status of each recommended resource, or nil if there is no recommendation.
+func (n *Synchronizer) resourceRecommendation(ctx context.Context, app *nais_io_v1alpha1.Application, logger log.Entry) ([]ResourceRecommendationStatus, error) {
+	if n.ResourceRecommendations == nil {
+		return nil, nil
+	}
+
+	key := client.ObjectKey{Namespace: app.GetNamespace(), Name: app.GetName()}
+
+	vpa := &autoscaling_k8s_io_v1.VerticalPodAutoscaler{}
+	err := n.Get(ctx, key, vpa)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	threshold := n.ResourceOptions.VerticalPodAutoscaler.ReportThreshold
+	requests := verticalpodautoscaler.Requests(*app.Spec.Resources)
+	deviations := verticalpodautoscaler.Deviations(vpa, app.GetName(), requests, threshold)
+	statuses := ResourceRecommendationsStatus(verticalpodautoscaler.Target(vpa, app.GetName()), requests, deviations)
+
+	// Recommendations are applied automatically; there is nothing for the team to act upon.
+	if app.Annotations[verticalpodautoscaler.ModeAnnotation] == verticalpodautoscaler.ModeAuto {
+		return statuses, nil
+	}
+
+	if !n.ResourceRecommendations.changed(key, deviations) {
+		return statuses, nil
+	}
+
+	event := resource.CreateEvent(app, EventResourceRequestsRecommended, "Resource requests are close to the recommendation", "Normal")
+	if len(deviations) > 0 {
+		messages := make([]string, len(deviations))
+		for i, deviation := range deviations {
+			messages[i] = deviation.String()
+		}
+		message := fmt.Sprintf("Resource requests differ from the recommendation by more than a factor of %g: %s", threshold, strings.Join(messages, "; "))
+		event = resource.CreateEvent(app, EventResourceRequestsDeviate, message, "Warning")
+	}
+
+	_, err = n.reportEvent(ctx, event)
+	if err != nil {
+		logger.Errorf("Resource recommendation: unable to report event: %s", err)
+	}
+
+	return statuses, nil
+}
+
+// removeResourceRecommendation forgets the reported deviations of a deleted application.
+func (n *Synchronizer) removeResourceRecommendation(key client.ObjectKey) {
+	if n.ResourceRecommendations == nil {
+		return
+	}
+	n.ResourceRecommendations.remove(key)
+}
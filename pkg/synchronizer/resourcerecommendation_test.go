@@ -0,0 +1,41 @@
+package synchronizer_test
+
+import (
+	"testing"
+
+	"github.com/nais/naiserator/pkg/resourcecreator/verticalpodautoscaler"
+	"github.com/nais/naiserator/pkg/synchronizer"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestResourceRecommendationsStatus(t *testing.T) {
+	requests := corev1.ResourceList{
+		corev1.ResourceCPU: k8sresource.MustParse("200m"),
+	}
+
+	t.Run("no recommendation yet", func(t *testing.T) {
+		assert.Nil(t, synchronizer.ResourceRecommendationsStatus(nil, requests, nil))
+	})
+
+	t.Run("recommendations are sorted and compared with requests", func(t *testing.T) {
+		target := corev1.ResourceList{
+			corev1.ResourceMemory: k8sresource.MustParse("1Gi"),
+			corev1.ResourceCPU:    k8sresource.MustParse("25m"),
+		}
+		deviations := []verticalpodautoscaler.Deviation{
+			{
+				Resource:    corev1.ResourceCPU,
+				Requested:   requests[corev1.ResourceCPU],
+				Recommended: target[corev1.ResourceCPU],
+			},
+		}
+
+		statuses := synchronizer.ResourceRecommendationsStatus(target, requests, deviations)
+		assert.Equal(t, []synchronizer.ResourceRecommendationStatus{
+			{Resource: "cpu", Requested: "200m", Recommended: "25m", Deviates: true},
+			{Resource: "memory", Recommended: "1Gi"},
+		}, statuses)
+	})
+}
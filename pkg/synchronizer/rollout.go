@@ -2,6 +2,7 @@ package synchronizer
 
 import (
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/resourcecreator/scalingschedule"
 	appsv1 "k8s.io/api/apps/v1"
 )
 
@@ -12,6 +13,7 @@ type Rollout struct {
 	ResourceOperations  resource.Operations
 	CorrelationID       string
 	SynchronizationHash string
+	ScalingSchedule     *scalingschedule.State
 }
 
 // SetCurrentDeployment makes sure newly created Deployment objects matches autoscaling properties of an
@@ -23,9 +25,34 @@ type Rollout struct {
 //
 // The number of replicas is set to whichever is highest: the current number of replicas (which might be zero),
 // or the default number of replicas.
+//
+// Applications with a scaling schedule are scaled to the scheduled number of replicas outside of the schedule,
+// and to at least the minimum number of replicas within it, as they might have been scaled down.
 func (r *Rollout) SetCurrentDeployment(deployment *appsv1.Deployment, currentReplicasMin int) {
-	if deployment != nil && deployment.Spec.Replicas != nil {
-		r.ResourceOptions.NumReplicas = max(1, *deployment.Spec.Replicas)
+	var replicas *int32
+	if deployment != nil {
+		replicas = deployment.Spec.Replicas
+	}
+	r.setCurrentReplicas(replicas, currentReplicasMin)
+}
+
+// SetCurrentStatefulSet is the equivalent of SetCurrentDeployment for applications running as StatefulSets.
+func (r *Rollout) SetCurrentStatefulSet(statefulSet *appsv1.StatefulSet, currentReplicasMin int) {
+	var replicas *int32
+	if statefulSet != nil {
+		replicas = statefulSet.Spec.Replicas
+	}
+	r.setCurrentReplicas(replicas, currentReplicasMin)
+}
+
+func (r *Rollout) setCurrentReplicas(replicas *int32, currentReplicasMin int) {
+	if r.ScalingSchedule != nil && r.ScalingSchedule.ScaledDown {
+		r.ResourceOptions.ScaledDown = true
+		r.ResourceOptions.NumReplicas = int32(r.ScalingSchedule.Replicas)
+	} else if r.ScalingSchedule != nil && replicas != nil {
+		r.ResourceOptions.NumReplicas = max(int32(currentReplicasMin), *replicas)
+	} else if replicas != nil {
+		r.ResourceOptions.NumReplicas = max(1, *replicas)
 	} else {
 		r.ResourceOptions.NumReplicas = max(1, int32(currentReplicasMin))
 	}
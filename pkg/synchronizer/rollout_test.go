@@ -1 +1,45 @@
 package synchronizer_test
+
+import (
+	"testing"
+
+	"github.com/nais/naiserator/pkg/resourcecreator/scalingschedule"
+	"github.com/nais/naiserator/pkg/synchronizer"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+func deploymentWithReplicas(replicas int32) *appsv1.Deployment {
+	return &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: &replicas}}
+}
+
+func TestSetCurrentDeployment(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		deployment *appsv1.Deployment
+		schedule   *scalingschedule.State
+		replicas   int32
+		scaledDown bool
+	}{
+		{"new deployment", nil, nil, 2, false},
+		{"replicas are carried over", deploymentWithReplicas(5), nil, 5, false},
+		{"at least one replica", deploymentWithReplicas(0), nil, 1, false},
+		{"scaled down outside of schedule", deploymentWithReplicas(5), &scalingschedule.State{ScaledDown: true}, 0, true},
+		{"scaled up to replicas.min within schedule", deploymentWithReplicas(0), &scalingschedule.State{}, 2, false},
+		{"autoscaled replicas are carried over within schedule", deploymentWithReplicas(3), &scalingschedule.State{}, 3, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			rollout := &synchronizer.Rollout{ScalingSchedule: test.schedule}
+			rollout.SetCurrentDeployment(test.deployment, 2)
+			assert.Equal(t, test.replicas, rollout.ResourceOptions.NumReplicas)
+			assert.Equal(t, test.scaledDown, rollout.ResourceOptions.ScaledDown)
+		})
+	}
+}
+
+func TestSetCurrentStatefulSet(t *testing.T) {
+	replicas := int32(3)
+	rollout := &synchronizer.Rollout{}
+	rollout.SetCurrentStatefulSet(&appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: &replicas}}, 2)
+	assert.Equal(t, replicas, rollout.ResourceOptions.NumReplicas)
+}
@@ -0,0 +1,77 @@
+package synchronizer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/resourcecreator/scalingschedule"
+	log "github.com/sirupsen/logrus"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Machine readable event "Reason" field for applications scaled down outside of their scaling schedule.
+const EventScaledDown = "ScaledDown"
+
+// scalingSchedule evaluates the scaling schedule of an application at the given time.
+// A nil state is returned if the application has no schedule, or if scheduled scaling is disabled.
+// Application defaults must be applied first.
+func (n *Synchronizer) scalingSchedule(app *nais_io_v1alpha1.Application, now time.Time) (*scalingschedule.State, error) {
+	if !n.ResourceOptions.ScalingScheduleEnabled {
+		return nil, nil
+	}
+
+	schedule, err := scalingschedule.Parse(app.Annotations)
+	if err != nil || schedule == nil {
+		return nil, err
+	}
+
+	err = schedule.Validate(n.ResourceOptions.ScalingSchedule, app.Spec.Replicas.Max)
+	if err != nil {
+		return nil, fmt.Errorf("scaling schedule: %s", err)
+	}
+
+	state := schedule.Evaluate(n.ResourceOptions.ScalingSchedule, now)
+	return &state, nil
+}
+
+// scalingScheduleHash extends the synchronization hash with the scaling schedule, so that the application
+// is synchronized again whenever the schedule, or whether it is scaled down, changes.
+func scalingScheduleHash(hash string, app *nais_io_v1alpha1.Application, state *scalingschedule.State) string {
+	if state == nil {
+		return hash
+	}
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%t", hash, app.Annotations[scalingschedule.Annotation], state.ScaledDown)))
+	return fmt.Sprintf("%x", sum[:8])
+}
+
+// scalingScheduleRequeue schedules the next reconciliation of an application at the time its scaling schedule
+// changes state. Errors are reported by Prepare.
+func (n *Synchronizer) scalingScheduleRequeue(app *nais_io_v1alpha1.Application) ctrl.Result {
+	now := time.Now()
+	state, err := n.scalingSchedule(app, now)
+	if err != nil || state == nil || state.Next.IsZero() {
+		return ctrl.Result{}
+	}
+	return ctrl.Result{RequeueAfter: state.Next.Sub(now)}
+}
+
+// reportScaledDown tells the application owner why the application is not running.
+func (n *Synchronizer) reportScaledDown(ctx context.Context, app *nais_io_v1alpha1.Application, state *scalingschedule.State) {
+	if state == nil || !state.ScaledDown {
+		return
+	}
+
+	message := fmt.Sprintf("Scaled down to %d replicas outside of the scaling schedule", state.Replicas)
+	if !state.Next.IsZero() {
+		message = fmt.Sprintf("%s until %s, or until annotation '%s' is set", message, state.Next.Format(time.RFC3339), scalingschedule.ScaleUpUntilAnnotation)
+	}
+
+	_, err := n.reportEvent(ctx, resource.CreateEvent(app, EventScaledDown, message, "Normal"))
+	if err != nil {
+		log.WithFields(app.LogFields()).Errorf("While creating an event for the scaling schedule, an error occurred: %s", err)
+	}
+}
@@ -0,0 +1,30 @@
+package synchronizer
+
+import (
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	"github.com/nais/naiserator/pkg/resourcecreator/resource"
+	"github.com/nais/naiserator/pkg/resourcecreator/statefulset"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// isStatefulSet returns true if the application is rendered as a StatefulSet instead of a Deployment.
+// Invalid StatefulSet settings are reported when the application is prepared.
+func isStatefulSet(app *nais_io_v1alpha1.Application, resourceOptions resource.Options) bool {
+	_, ok := app.Annotations[statefulset.Annotation]
+	return ok && resourceOptions.StatefulSetEnabled
+}
+
+// statefulSetComplete considers a StatefulSet to be complete once all of its desired replicas
+// are updated and ready, and the update has been observed by the StatefulSet controller.
+func statefulSetComplete(statefulSet *appsv1.StatefulSet) bool {
+	replicas := int32(1)
+	if statefulSet.Spec.Replicas != nil {
+		replicas = *statefulSet.Spec.Replicas
+	}
+	status := statefulSet.Status
+	return status.ObservedGeneration >= statefulSet.Generation &&
+		status.Replicas == replicas &&
+		status.ReadyReplicas == replicas &&
+		status.UpdatedReplicas == replicas &&
+		status.CurrentRevision == status.UpdateRevision
+}
@@ -0,0 +1,62 @@
+package synchronizer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusAnnotationPrefix is prepended to status fields that are not part of the Application CRD.
+// These fields are stored as JSON encoded annotations on the Application, and are not part of the synchronization hash.
+const StatusAnnotationPrefix = "status.nais.io/"
+
+// setStatusAnnotation stores a JSON encoded status field on an object without persisting it.
+// A nil value removes the field. Returns true if the stored value was changed.
+func setStatusAnnotation(object metav1.Object, field string, value interface{}) (bool, error) {
+	key := StatusAnnotationPrefix + field
+	annotations := object.GetAnnotations()
+	previous, exists := annotations[key]
+
+	if value == nil {
+		if !exists {
+			return false, nil
+		}
+		delete(annotations, key)
+		object.SetAnnotations(annotations)
+		return true, nil
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("encode status field %s: %w", field, err)
+	}
+	if exists && previous == string(encoded) {
+		return false, nil
+	}
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[key] = string(encoded)
+	object.SetAnnotations(annotations)
+
+	return true, nil
+}
+
+// updateStatusAnnotation stores a JSON encoded status field on the newest version of an application.
+// A nil value removes the field. Returns true if the stored value was changed.
+func (n *Synchronizer) updateStatusAnnotation(ctx context.Context, app *nais_io_v1alpha1.Application, field string, value interface{}) (bool, error) {
+	changed := false
+	err := n.UpdateApplication(ctx, app, func(existing *nais_io_v1alpha1.Application) error {
+		var err error
+		changed, err = setStatusAnnotation(existing, field, value)
+		if err != nil || !changed {
+			return err
+		}
+		return n.Update(ctx, existing)
+	})
+
+	return changed, err
+}
@@ -11,7 +11,10 @@ import (
 	"github.com/nais/naiserator/pkg/kafka"
 	"github.com/nais/naiserator/pkg/metrics"
 	"github.com/nais/naiserator/pkg/naiserator/config"
+	"github.com/nais/naiserator/pkg/policygraph"
 	"github.com/nais/naiserator/pkg/resourcecreator"
+	"github.com/nais/naiserator/pkg/resourcecreator/google"
+	"github.com/nais/naiserator/pkg/resourcecreator/google/gcp"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	naiserator_scheme "github.com/nais/naiserator/pkg/scheme"
 	"github.com/nais/naiserator/updater"
@@ -44,12 +47,14 @@ const (
 // If the child resources does not match the Application spec, the resources are updated.
 type Synchronizer struct {
 	client.Client
-	RolloutMonitor  map[client.ObjectKey]RolloutMonitor
-	SimpleClient    client.Client
-	Scheme          *runtime.Scheme
-	ResourceOptions resource.Options
-	Config          config.Config
-	Kafka           kafka.Interface
+	RolloutMonitor          map[client.ObjectKey]RolloutMonitor
+	SimpleClient            client.Client
+	Scheme                  *runtime.Scheme
+	ResourceOptions         resource.Options
+	Config                  config.Config
+	Kafka                   kafka.Interface
+	AccessPolicyGraph       *policygraph.Graph
+	ResourceRecommendations *ResourceRecommendations
 }
 
 // Creates a Kubernetes event, or updates an existing one with an incremented counter
@@ -106,6 +111,8 @@ func (n *Synchronizer) ReconcileApplication(req ctrl.Request) (ctrl.Result, erro
 				"application": req.Name,
 			})
 			logger.Infof("Application has been deleted from Kubernetes")
+			n.removeAccessPolicy(policygraph.TypeApplication, req.NamespacedName)
+			n.removeResourceRecommendation(req.NamespacedName)
 
 			err = nil
 		}
@@ -132,6 +139,8 @@ func (n *Synchronizer) ReconcileApplication(req ctrl.Request) (ctrl.Result, erro
 		}
 	}()
 
+	n.checkAccessPolicy(ctx, app, policygraph.NodeFromApplication(app))
+
 	rollout, err := n.Prepare(app)
 	if err != nil {
 		app.Status.SynchronizationState = EventFailedPrepare
@@ -139,8 +148,11 @@ func (n *Synchronizer) ReconcileApplication(req ctrl.Request) (ctrl.Result, erro
 		return ctrl.Result{RequeueAfter: prepareRetryInterval}, nil
 	}
 
+	recommendationChanged := n.checkResourceRecommendation(ctx, app)
+
 	if rollout == nil {
-		changed = false
+		// Resource recommendations are stored along with the application status.
+		changed = recommendationChanged
 		logger.Debugf("Synchronization hash not changed; skipping synchronization")
 
 		// Application is not rolled out completely; start monitoring
@@ -148,7 +160,7 @@ func (n *Synchronizer) ReconcileApplication(req ctrl.Request) (ctrl.Result, erro
 			n.MonitorRollout(app, logger)
 		}
 
-		return ctrl.Result{}, nil
+		return requeueFirst(n.redisRequeue(ctx, app), n.scalingScheduleRequeue(app), n.strictEgressRequeue(app.Spec.AccessPolicy)), nil
 	}
 
 	logger = *log.WithFields(app.LogFields())
@@ -168,7 +180,7 @@ func (n *Synchronizer) ReconcileApplication(req ctrl.Request) (ctrl.Result, erro
 			app.Status.SynchronizationHash = rollout.SynchronizationHash // permanent failure
 			metrics.ApplicationsFailed.Inc()
 			n.reportError(ctx, app.Status.SynchronizationState, err, app)
-			err = nil
+			return n.scalingScheduleRequeue(app), nil
 		}
 		return ctrl.Result{}, err
 	}
@@ -186,15 +198,19 @@ func (n *Synchronizer) ReconcileApplication(req ctrl.Request) (ctrl.Result, erro
 	}
 
 	// Monitor the rollout status so that we can report a successfully completed rollout to NAIS deploy.
+	// Certificates for custom ingress domains are monitored along with the rollout.
 	n.MonitorRollout(app, logger)
 
-	return ctrl.Result{}, nil
+	n.reportScaledDown(ctx, app, rollout.ScalingSchedule)
+	n.reportStrictEgress(ctx, app, app.Spec.AccessPolicy, rollout.ResourceOptions, logger)
+
+	return requeueFirst(n.redisRequeue(ctx, app), n.scalingScheduleRequeue(app), n.strictEgressRequeue(app.Spec.AccessPolicy)), nil
 }
 
 // Unreferenced return all resources in cluster which was created by synchronizer previously, but is not included in the current rollout.
-func (n *Synchronizer) Unreferenced(ctx context.Context, rollout Rollout) ([]runtime.Object, error) {
+func (n *Synchronizer) Unreferenced(ctx context.Context, rollout Rollout) ([]client.Object, error) {
 	// Return true if a cluster resource also is applied with the rollout.
-	intersects := func(existing runtime.Object) bool {
+	intersects := func(existing client.Object) bool {
 		existingMeta, err := meta.Accessor(existing)
 		if err != nil {
 			log.Errorf("BUG: unable to determine TypeMeta for existing resource: %s", err)
@@ -219,15 +235,37 @@ func (n *Synchronizer) Unreferenced(ctx context.Context, rollout Rollout) ([]run
 	}
 
 	listers := naiserator_scheme.GenericListers()
+	listers = append(listers, naiserator_scheme.IngressListers(n.ResourceOptions.IngressV1beta1)...)
 	if len(n.ResourceOptions.GoogleProjectId) > 0 {
 		listers = append(listers, naiserator_scheme.GCPListers()...)
 	}
-	resources, err := updater.FindAll(ctx, n, n.Scheme, listers, rollout.Source)
+	if n.ResourceOptions.SecretManagerEnabled {
+		listers = append(listers, naiserator_scheme.SecretManagerListers()...)
+	}
+	if n.ResourceOptions.GatewayAPI {
+		listers = append(listers, naiserator_scheme.GatewayAPIListers()...)
+	}
+	if n.ResourceOptions.CertManagerEnabled {
+		listers = append(listers, naiserator_scheme.CertManagerListers()...)
+	}
+	// KEDA objects are always listed, so that they are removed when KEDA is turned off for an application or the cluster.
+	// Clusters without the KEDA CRDs are skipped when listing.
+	listers = append(listers, naiserator_scheme.KedaListers()...)
+	if n.ResourceOptions.LinkerdPolicyEnabled {
+		listers = append(listers, naiserator_scheme.LinkerdPolicyListers()...)
+	}
+	if n.ResourceOptions.VerticalPodAutoscalerEnabled {
+		listers = append(listers, naiserator_scheme.VerticalPodAutoscalerListers()...)
+	}
+	if n.Config.Features.StrictEgress {
+		listers = append(listers, naiserator_scheme.CiliumListers()...)
+	}
+	resources, err := updater.FindAll(ctx, n.Client, n.Scheme, listers, rollout.Source)
 	if err != nil {
 		return nil, fmt.Errorf("discovering unreferenced resources: %s", err)
 	}
 
-	unreferenced := make([]runtime.Object, 0, len(resources))
+	unreferenced := make([]client.Object, 0, len(resources))
 	for _, existing := range resources {
 		if !intersects(existing) {
 			unreferenced = append(unreferenced, existing)
@@ -278,6 +316,25 @@ func (n *Synchronizer) Prepare(app *nais_io_v1alpha1.Application) (*Rollout, err
 	if err != nil {
 		return nil, fmt.Errorf("BUG: create application hash: %s", err)
 	}
+	rollout.SynchronizationHash = annotationsHash(rollout.SynchronizationHash, app.GetAnnotations())
+
+	rollout.ResourceOptions.RedisHost, rollout.ResourceOptions.RedisPort, err = n.redisAddress(ctx, app)
+	if err != nil {
+		return nil, err
+	}
+	rollout.SynchronizationHash = redisHash(rollout.SynchronizationHash, rollout.ResourceOptions.RedisHost, rollout.ResourceOptions.RedisPort)
+
+	rollout.ScalingSchedule, err = n.scalingSchedule(app, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rollout.SynchronizationHash = scalingScheduleHash(rollout.SynchronizationHash, app, rollout.ScalingSchedule)
+
+	err = n.strictEgress(ctx, &rollout.ResourceOptions, app, app.Spec.AccessPolicy)
+	if err != nil {
+		return nil, err
+	}
+	rollout.SynchronizationHash = strictEgressHash(rollout.SynchronizationHash, rollout.ResourceOptions)
 
 	// Skip processing if application didn't change since last synchronization.
 	if app.Status.SynchronizationHash == rollout.SynchronizationHash {
@@ -294,10 +351,20 @@ func (n *Synchronizer) Prepare(app *nais_io_v1alpha1.Application) (*Rollout, err
 	// Make a query to Kubernetes for this application's previous deployment.
 	// The number of replicas is significant, so we need to carry it over to match
 	// this next rollout.
-	previousDeployment := &apps.Deployment{}
-	err = n.Get(ctx, client.ObjectKey{Name: app.GetName(), Namespace: app.GetNamespace()}, previousDeployment)
-	if err != nil && !errors.IsNotFound(err) {
-		return nil, fmt.Errorf("query existing deployment: %s", err)
+	var previousDeployment *apps.Deployment
+	var previousStatefulSet *apps.StatefulSet
+	if isStatefulSet(app, n.ResourceOptions) {
+		previousStatefulSet = &apps.StatefulSet{}
+		err = n.Get(ctx, client.ObjectKey{Name: app.GetName(), Namespace: app.GetNamespace()}, previousStatefulSet)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("query existing statefulset: %s", err)
+		}
+	} else {
+		previousDeployment = &apps.Deployment{}
+		err = n.Get(ctx, client.ObjectKey{Name: app.GetName(), Namespace: app.GetNamespace()}, previousDeployment)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("query existing deployment: %s", err)
+		}
 	}
 
 	// Retrieve current namespace to check for labels and annotations
@@ -307,7 +374,7 @@ func (n *Synchronizer) Prepare(app *nais_io_v1alpha1.Application) (*Rollout, err
 		return nil, fmt.Errorf("query existing namespace: %s", err)
 	}
 
-	if app.Spec.GCP != nil {
+	if gcp.RequiresTeamProject(app, app.Spec.GCP) {
 		// App requests gcp resources, verify we've got a GCP team project ID
 		projectID, ok := namespace.Annotations["cnrm.cloud.google.com/project-id"]
 		if !ok {
@@ -315,6 +382,7 @@ func (n *Synchronizer) Prepare(app *nais_io_v1alpha1.Application) (*Rollout, err
 			return nil, fmt.Errorf("GCP resources requested, but no team project ID annotation set on namespace %s (not running on GCP?)", app.GetNamespace())
 		}
 		rollout.ResourceOptions.GoogleTeamProjectId = projectID
+		rollout.ResourceOptions.GoogleTeamProjectNumber = namespace.Annotations[google.ProjectNumberAnnotation]
 	}
 
 	// Create Linkerd resources only if feature is enabled and namespace is Linkerd-enabled
@@ -322,13 +390,22 @@ func (n *Synchronizer) Prepare(app *nais_io_v1alpha1.Application) (*Rollout, err
 		rollout.ResourceOptions.Linkerd = true
 	}
 
-	rollout.SetCurrentDeployment(previousDeployment, app.Spec.Replicas.Min)
+	if previousStatefulSet != nil {
+		rollout.SetCurrentStatefulSet(previousStatefulSet, app.Spec.Replicas.Min)
+	} else {
+		rollout.SetCurrentDeployment(previousDeployment, app.Spec.Replicas.Min)
+	}
 	rollout.ResourceOperations, err = resourcecreator.CreateApplication(app, rollout.ResourceOptions)
 
 	if err != nil {
 		return nil, fmt.Errorf("creating cluster resource operations: %s", err)
 	}
 
+	err = n.checkIngressCollisions(ctx, *rollout)
+	if err != nil {
+		return nil, err
+	}
+
 	return rollout, nil
 }
 
@@ -342,13 +419,13 @@ func (n *Synchronizer) ClusterOperations(ctx context.Context, rollout Rollout) [
 	for _, rop := range rollout.ResourceOperations {
 		switch rop.Operation {
 		case resource.OperationCreateOrUpdate:
-			fn = updater.CreateOrUpdate(ctx, n, n.Scheme, rop.Resource)
+			fn = updater.CreateOrUpdate(ctx, n.Client, n.Scheme, rop.Resource)
 		case resource.OperationCreateOrRecreate:
-			fn = updater.CreateOrRecreate(ctx, n, rop.Resource)
+			fn = updater.CreateOrRecreate(ctx, n.Client, rop.Resource)
 		case resource.OperationCreateIfNotExists:
-			fn = updater.CreateIfNotExists(ctx, n, rop.Resource)
+			fn = updater.CreateIfNotExists(ctx, n.Client, rop.Resource)
 		case resource.OperationDeleteIfExists:
-			fn = updater.DeleteIfExists(ctx, n, rop.Resource)
+			fn = updater.DeleteIfExists(ctx, n.Client, rop.Resource)
 		default:
 			return []func() error{
 				func() error {
@@ -368,7 +445,7 @@ func (n *Synchronizer) ClusterOperations(ctx context.Context, rollout Rollout) [
 		})
 	} else {
 		for _, rsrc := range unreferenced {
-			deletes = append(deletes, updater.DeleteIfExists(ctx, n, rsrc))
+			deletes = append(deletes, updater.DeleteIfExists(ctx, n.Client, rsrc))
 		}
 	}
 
@@ -398,3 +475,14 @@ func max(a, b int32) int32 {
 	}
 	return b
 }
+
+// requeueFirst combines reconcile results, requeueing at the earliest time any of them asks for.
+func requeueFirst(results ...ctrl.Result) ctrl.Result {
+	combined := ctrl.Result{}
+	for _, result := range results {
+		if result.RequeueAfter > 0 && (combined.RequeueAfter == 0 || result.RequeueAfter < combined.RequeueAfter) {
+			combined.RequeueAfter = result.RequeueAfter
+		}
+	}
+	return combined
+}
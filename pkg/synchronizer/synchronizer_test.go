@@ -16,7 +16,6 @@ import (
 	nais_io_v1alpha1 "github.com/nais/liberator/pkg/apis/nais.io/v1alpha1"
 	sql_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/sql.cnrm.cloud.google.com/v1beta1"
 	"github.com/nais/liberator/pkg/crd"
-	liberator_scheme "github.com/nais/liberator/pkg/scheme"
 	"github.com/nais/naiserator/pkg/naiserator/config"
 	naiserator_scheme "github.com/nais/naiserator/pkg/scheme"
 	"github.com/nais/naiserator/pkg/synchronizer"
@@ -24,7 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	networkingv1beta1 "k8s.io/api/networking/v1beta1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -55,7 +54,7 @@ func newTestRig(options resource.Options) (*testRig, error) {
 		return nil, fmt.Errorf("setup Kubernetes test environment: %w", err)
 	}
 
-	rig.scheme, err = liberator_scheme.All()
+	rig.scheme, err = naiserator_scheme.All()
 	if err != nil {
 		return nil, fmt.Errorf("setup scheme: %w", err)
 	}
@@ -136,14 +135,14 @@ func TestSynchronizer(t *testing.T) {
 	})
 
 	// Test that a resource has been created in the fake cluster
-	testResource := func(resource runtime.Object, objectKey client.ObjectKey) {
+	testResource := func(resource client.Object, objectKey client.ObjectKey) {
 		err := rig.client.Get(ctx, objectKey, resource)
 		assert.NoError(t, err)
 		assert.NotNil(t, resource)
 	}
 
 	// Test that a resource does not exist in the fake cluster
-	testResourceNotExist := func(resource runtime.Object, objectKey client.ObjectKey) {
+	testResourceNotExist := func(resource client.Object, objectKey client.ObjectKey) {
 		err := rig.client.Get(ctx, objectKey, resource)
 		assert.True(t, errors.IsNotFound(err), "the resource found in the cluster should not be there")
 	}
@@ -161,7 +160,7 @@ func TestSynchronizer(t *testing.T) {
 	app.Spec.Ingresses = []nais_io_v1.Ingress{"https://foo.bar"}
 	err = ingress.Create(app, ast, resourceOptions, app.Spec.Ingresses, app.Spec.Liveness.Path, app.Spec.Service.Protocol, app.Annotations)
 	assert.NoError(t, err)
-	ing := ast.Operations[0].Resource.(*networkingv1beta1.Ingress)
+	ing := ast.Operations[0].Resource.(*networkingv1.Ingress)
 	app.Spec.Ingresses = []nais_io_v1.Ingress{}
 	err = rig.client.Create(ctx, ing)
 	if err != nil || len(ing.Spec.Rules) == 0 {
@@ -173,7 +172,7 @@ func TestSynchronizer(t *testing.T) {
 	app.Spec.Ingresses = []nais_io_v1.Ingress{"https://foo.bar"}
 	err = ingress.Create(app, ast, resourceOptions, app.Spec.Ingresses, app.Spec.Liveness.Path, app.Spec.Service.Protocol, app.Annotations)
 	assert.NoError(t, err)
-	ing = ast.Operations[1].Resource.(*networkingv1beta1.Ingress)
+	ing = ast.Operations[1].Resource.(*networkingv1.Ingress)
 	ing.SetName("disowned-ingress")
 	ing.SetOwnerReferences(nil)
 	app.Spec.Ingresses = []nais_io_v1.Ingress{}
@@ -190,7 +189,7 @@ func TestSynchronizer(t *testing.T) {
 			Name:      app.Name,
 		},
 	}
-	result, err := rig.synchronizer.Reconcile(req)
+	result, err := rig.synchronizer.Reconcile(ctx, req)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
 
@@ -214,7 +213,7 @@ func TestSynchronizer(t *testing.T) {
 	testResource(&corev1.ServiceAccount{}, objectKey)
 
 	// Test that the Ingress resource was removed
-	testResourceNotExist(&networkingv1beta1.Ingress{}, objectKey)
+	testResourceNotExist(&networkingv1.Ingress{}, objectKey)
 
 	// Test that a Synchronized event was generated and has the correct deployment correlation id
 	eventList := &corev1.EventList{}
@@ -231,14 +230,14 @@ func TestSynchronizer(t *testing.T) {
 	app.Annotations[nais_io_v1.DeploymentCorrelationIDAnnotation] = "new-deploy-id"
 	err = rig.client.Update(ctx, app)
 	assert.NoError(t, err)
-	result, err = rig.synchronizer.Reconcile(req)
+	result, err = rig.synchronizer.Reconcile(ctx, req)
 
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
 	testResource(&appsv1.Deployment{}, objectKey)
 	testResource(&corev1.Service{}, objectKey)
 	testResource(&corev1.ServiceAccount{}, objectKey)
-	testResource(&networkingv1beta1.Ingress{}, client.ObjectKey{Name: "disowned-ingress", Namespace: app.Namespace})
+	testResource(&networkingv1.Ingress{}, client.ObjectKey{Name: "disowned-ingress", Namespace: app.Namespace})
 
 	// Test that the naiserator event was updated with increased count and new correlation id
 	err = rig.client.List(ctx, eventList)
@@ -310,7 +309,7 @@ func TestSynchronizerResourceOptions(t *testing.T) {
 		},
 	}
 
-	result, err := rig.synchronizer.Reconcile(req)
+	result, err := rig.synchronizer.Reconcile(ctx, req)
 	assert.NoError(t, err)
 	assert.Equal(t, ctrl.Result{}, result)
 
@@ -0,0 +1,5 @@
+package util
+
+func Boolp(b bool) *bool {
+	return &b
+}
@@ -42,3 +42,16 @@ func ResolveIngressClass(host string, mappings []config.GatewayMapping) *string
 	}
 	return nil
 }
+
+func ResolveGateway(host string, mappings []config.GatewayMapping) *config.Gateway {
+	for _, mapping := range mappings {
+		if !strings.HasSuffix(host, mapping.DomainSuffix) {
+			continue
+		}
+		if len(mapping.Gateway.Name) == 0 {
+			return nil
+		}
+		return &mapping.Gateway
+	}
+	return nil
+}
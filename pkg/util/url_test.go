@@ -5,6 +5,7 @@ import (
 	"net/url"
 	"testing"
 
+	"github.com/nais/naiserator/pkg/naiserator/config"
 	"github.com/nais/naiserator/pkg/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -39,3 +40,14 @@ func TestValidateUrl(t *testing.T) {
 		assert.Error(t, util.ValidateUrl(u), "expected URL '%s' to fail validation", s)
 	}
 }
+
+func TestResolveGateway(t *testing.T) {
+	mappings := []config.GatewayMapping{
+		{DomainSuffix: ".nginx.tld", IngressClass: "nginx"},
+		{DomainSuffix: ".tld", Gateway: config.Gateway{Name: "gw", Namespace: "gateways"}},
+	}
+
+	assert.Equal(t, "gw", util.ResolveGateway("foo.tld", mappings).Name)
+	assert.Nil(t, util.ResolveGateway("foo.nginx.tld", mappings), "the most specific mapping has no gateway")
+	assert.Nil(t, util.ResolveGateway("foo.other", mappings))
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	sql_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/sql.cnrm.cloud.google.com/v1beta1"
-	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/liberator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
 	liberator_scheme "github.com/nais/liberator/pkg/scheme"
+	storage_cnrm_cloud_google_com_v1beta1 "github.com/nais/naiserator/pkg/apis/storage.cnrm.cloud.google.com/v1beta1"
 	"github.com/nais/naiserator/pkg/resourcecreator/resource"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -18,18 +18,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func CreateOrUpdate(ctx context.Context, cli client.Client, scheme *runtime.Scheme, resource runtime.Object) func() error {
+func CreateOrUpdate(ctx context.Context, cli client.Client, scheme *runtime.Scheme, resource client.Object) func() error {
 	return func() error {
 		log.Infof("CreateOrUpdate %s", liberator_scheme.TypeName(resource))
 		existing, err := scheme.New(resource.GetObjectKind().GroupVersionKind())
 		if err != nil {
 			return fmt.Errorf("internal error: %w", err)
 		}
-		objectKey, err := client.ObjectKeyFromObject(resource)
-		if err != nil {
-			return fmt.Errorf("unable to derive object key: %w", err)
+		existingObject, ok := existing.(client.Object)
+		if !ok {
+			return fmt.Errorf("internal error: %T is not a Kubernetes object", existing)
 		}
-		err = cli.Get(ctx, objectKey, existing)
+		err = cli.Get(ctx, client.ObjectKeyFromObject(resource), existingObject)
 
 		if errors.IsNotFound(err) {
 			err = cli.Create(ctx, resource)
@@ -52,7 +52,7 @@ func CreateOrUpdate(ctx context.Context, cli client.Client, scheme *runtime.Sche
 	}
 }
 
-func CreateOrRecreate(ctx context.Context, cli client.Client, resource runtime.Object) func() error {
+func CreateOrRecreate(ctx context.Context, cli client.Client, resource client.Object) func() error {
 	return func() error {
 		log.Infof("CreateOrRecreate %s", liberator_scheme.TypeName(resource))
 		err := cli.Delete(ctx, resource)
@@ -63,7 +63,7 @@ func CreateOrRecreate(ctx context.Context, cli client.Client, resource runtime.O
 	}
 }
 
-func CreateIfNotExists(ctx context.Context, cli client.Client, resource runtime.Object) func() error {
+func CreateIfNotExists(ctx context.Context, cli client.Client, resource client.Object) func() error {
 	return func() error {
 		log.Infof("CreateIfNotExists %s", liberator_scheme.TypeName(resource))
 		err := cli.Create(ctx, resource)
@@ -74,7 +74,7 @@ func CreateIfNotExists(ctx context.Context, cli client.Client, resource runtime.
 	}
 }
 
-func DeleteIfExists(ctx context.Context, cli client.Client, resource runtime.Object) func() error {
+func DeleteIfExists(ctx context.Context, cli client.Client, resource client.Object) func() error {
 	return func() error {
 		log.Infof("DeleteIfExists %s", liberator_scheme.TypeName(resource))
 		err := cli.Delete(ctx, resource)
@@ -86,7 +86,7 @@ func DeleteIfExists(ctx context.Context, cli client.Client, resource runtime.Obj
 }
 
 // Find all Kubernetes resource matching label selector 'app=NAME' for all specified types
-func FindAll(ctx context.Context, cli client.Client, scheme *runtime.Scheme, types []runtime.Object, source resource.Source) ([]runtime.Object, error) {
+func FindAll(ctx context.Context, cli client.Client, scheme *runtime.Scheme, types []client.ObjectList, source resource.Source) ([]client.Object, error) {
 	// Set up label selector 'app=NAME'
 	labelSelector := labels.NewSelector()
 	labelreq, err := labels.NewRequirement("app", selection.Equals, []string{source.GetName()})
@@ -98,16 +98,24 @@ func FindAll(ctx context.Context, cli client.Client, scheme *runtime.Scheme, typ
 		LabelSelector: labelSelector,
 	}
 
-	resources := make([]runtime.Object, 0)
+	resources := make([]client.Object, 0)
 
 	for _, obj := range types {
 		err = cli.List(ctx, obj, listopt)
+		// Resources cannot exist if their custom resource definition is not installed in the cluster.
+		if meta.IsNoMatchError(err) {
+			log.Debugf("skipping %T: %s", obj, err)
+			continue
+		}
 		if err != nil {
 			return nil, fmt.Errorf("list %T: %w", obj, err)
 		}
 
 		_ = meta.EachListItem(obj, func(item runtime.Object) error {
-			resources = append(resources, item)
+			object, ok := item.(client.Object)
+			if ok {
+				resources = append(resources, object)
+			}
 			return nil
 		})
 	}
@@ -176,8 +184,8 @@ func CopyImmutable(src, dst runtime.Object) error {
 	return nil
 }
 
-func withOwnerReference(source resource.Source, resources []runtime.Object) []runtime.Object {
-	owned := make([]runtime.Object, 0, len(resources))
+func withOwnerReference(source resource.Source, resources []client.Object) []client.Object {
+	owned := make([]client.Object, 0, len(resources))
 
 	hasOwnerReference := func(r runtime.Object) (bool, error) {
 		m, err := meta.Accessor(r)